@@ -6,8 +6,22 @@ import (
 	"os/exec"
 )
 
-func Run(cmd string, args ...string) (string, error) {
+// Runner runs external commands and returns their output.
+type Runner interface {
+	Run(cmd string, args ...string) (string, error)
+}
+
+// ExecRunner runs commands as subprocesses.
+type ExecRunner struct {
+	// Dir is the working directory to run commands in. If empty, the current working directory is used.
+	Dir string
+}
+
+var _ Runner = ExecRunner{}
+
+func (r ExecRunner) Run(cmd string, args ...string) (string, error) {
 	c := exec.Command(cmd, args...)
+	c.Dir = r.Dir
 	c.Stderr = os.Stderr
 
 	result, err := c.Output()
@@ -17,3 +31,8 @@ func Run(cmd string, args ...string) (string, error) {
 
 	return string(result), nil
 }
+
+// Run runs a command in the current working directory.
+func Run(cmd string, args ...string) (string, error) {
+	return ExecRunner{}.Run(cmd, args...)
+}
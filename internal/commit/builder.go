@@ -8,12 +8,13 @@ import (
 	"time"
 
 	"github.com/josephnaberhaus/gauthordle/internal/config"
+	"github.com/josephnaberhaus/gauthordle/internal/git"
 )
 
 type FilterOption func(filter *Filter) error
 
 func BuildFilter(options ...FilterOption) (*Filter, error) {
-	filter := new(Filter)
+	filter := &Filter{redactor: new(Redactor)}
 	for _, opt := range options {
 		err := opt(filter)
 		if err != nil {
@@ -41,6 +42,47 @@ func WithConfig(cfg config.Config) FilterOption {
 			}
 		}
 
+		for _, pattern := range cfg.RedactPatterns {
+			r, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+			}
+			filter.redactor.patterns = append(filter.redactor.patterns, r)
+		}
+
+		switch cfg.CoAuthors {
+		case "", config.CoAuthorsIgnore, config.CoAuthorsAccept:
+		case config.CoAuthorsExclude:
+			filter.excludeCoAuthored = true
+		default:
+			return fmt.Errorf("invalid co_authors option %q", cfg.CoAuthors)
+		}
+
+		filter.aliasesByEmail = map[string]identity{}
+		filter.aliasesByName = map[string]identity{}
+		handlesByEmail := map[string][]string{}
+		for _, authorAlias := range cfg.AuthorAliases {
+			if authorAlias.Email == "" {
+				return fmt.Errorf("author alias %q is missing an email", authorAlias.Name)
+			}
+
+			canonical := identity{
+				name:  authorAlias.Name,
+				email: strings.ToLower(authorAlias.Email),
+			}
+			filter.aliasesByEmail[canonical.email] = canonical
+			handlesByEmail[canonical.email] = append(handlesByEmail[canonical.email], authorAlias.Handles...)
+			for _, email := range authorAlias.Emails {
+				filter.aliasesByEmail[strings.ToLower(email)] = canonical
+			}
+			for _, name := range authorAlias.Names {
+				filter.aliasesByName[strings.ToLower(name)] = canonical
+			}
+		}
+		filter.redactor.aliasesByEmail = filter.aliasesByEmail
+		filter.redactor.aliasesByName = filter.aliasesByName
+		filter.redactor.handlesByEmail = handlesByEmail
+
 		filter.teams = make(map[string]map[string]struct{}, len(cfg.Teams))
 		for name, team := range cfg.Teams {
 			for _, email := range team {
@@ -48,7 +90,11 @@ func WithConfig(cfg config.Config) FilterOption {
 					filter.teams[name] = map[string]struct{}{}
 				}
 
+				// Teams may list any of a person's e-mails.
 				email = strings.ToLower(email)
+				if canonical, ok := filter.aliasesByEmail[email]; ok {
+					email = canonical.email
+				}
 				filter.teams[name][email] = struct{}{}
 			}
 		}
@@ -57,6 +103,23 @@ func WithConfig(cfg config.Config) FilterOption {
 	}
 }
 
+func WithHistory(history git.History) FilterOption {
+	return func(filter *Filter) error {
+		filter.history = history
+
+		return nil
+	}
+}
+
+// WithRef reads commits reachable from the given revision instead of HEAD.
+func WithRef(ref string) FilterOption {
+	return func(filter *Filter) error {
+		filter.ref = ref
+
+		return nil
+	}
+}
+
 func WithStartTime(startTime time.Time) FilterOption {
 	return func(filter *Filter) error {
 		filter.startTime = startTime
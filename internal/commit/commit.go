@@ -1,9 +1,9 @@
 package commit
 
 import (
+	"cmp"
 	"fmt"
 	"regexp"
-	"slices"
 	"strings"
 	"time"
 
@@ -11,6 +11,10 @@ import (
 )
 
 type Filter struct {
+	// history is where commits are read from.
+	history git.History
+	// ref is the revision to read commits from. If empty, HEAD is used.
+	ref string
 	// startTime specifies the oldest commit to return.
 	// endTime specifies the earliest commit to return.
 	startTime, endTime time.Time
@@ -23,9 +27,26 @@ type Filter struct {
 	team string
 	// teams is a map from team name to a set of e-mails for the members of the team.
 	teams map[string]map[string]struct{}
+	// excludeCoAuthored specifies whether commits with co-authors should be removed.
+	excludeCoAuthored bool
+	// aliasesByEmail is a map from a lower-cased e-mail to the canonical identity of the person who uses it.
+	aliasesByEmail map[string]identity
+	// aliasesByName is a map from a lower-cased name to the canonical identity of the person who uses it.
+	aliasesByName map[string]identity
+	// redactor redacts the subjects. It's never nil.
+	redactor *Redactor
+}
+
+type identity struct {
+	// name is empty if the most recently used name should be kept.
+	name  string
+	email string
 }
 
 func (f *Filter) GetCommits() ([]git.Commit, error) {
+	if f.history == nil {
+		return nil, fmt.Errorf("no history specified")
+	}
 	if f.startTime.IsZero() {
 		return nil, fmt.Errorf("no start time specified")
 	}
@@ -33,18 +54,22 @@ func (f *Filter) GetCommits() ([]git.Commit, error) {
 		return nil, fmt.Errorf("no end time specified")
 	}
 
-	commits, err := git.GetCommits(f.startTime, f.endTime)
+	commits, err := f.history.GetCommits(f.ref, f.startTime, f.endTime)
 	if err != nil {
 		return nil, err
 	}
 
 	type filterFunc func([]git.Commit) []git.Commit
 	filters := []filterFunc{
+		f.resolveAliases,
+		// Co-authorship is decided before bots and exclusions are removed from the co-authors.
+		f.filterCoAuthored,
 		f.filterExclusions,
 		f.filterByTeam,
 		f.filterOutBots,
 		f.filterCommitSubjects,
 		f.consolidateAuthorDetails,
+		f.redactSubjects,
 	}
 	for _, filter := range filters {
 		commits = filter(commits)
@@ -58,40 +83,115 @@ func (f *Filter) GetCommits() ([]git.Commit, error) {
 func (f *Filter) filterOutBots(commits []git.Commit) []git.Commit {
 	var result []git.Commit
 	for _, commit := range commits {
-		// E-mails with "noreply" in them are usually associated with bots.
-		if strings.Contains(commit.AuthorEmail, "noreply") {
+		if isBot(commit.AuthorName, commit.AuthorEmail) {
 			continue
 		}
 
-		// If the author's name contains the word "robot" that's a pretty good indication that it's a robot.
-		if strings.Contains(strings.ToLower(commit.AuthorName), "robot") {
+		commit.CoAuthors = removeIdentities(commit.CoAuthors, isBotCoAuthor)
+		result = append(result, commit)
+	}
+
+	return result
+}
+
+func isBot(name, email string) bool {
+	// E-mails with "noreply" in them are usually associated with bots.
+	if strings.Contains(email, "noreply") {
+		return true
+	}
+
+	// If the author's name contains the word "robot" that's a pretty good indication that it's a robot.
+	if strings.Contains(strings.ToLower(name), "robot") {
+		return true
+	}
+
+	return false
+}
+
+// githubUserEmailDomain is the domain of the private addresses GitHub gives its users. These are what GitHub writes into
+// the Co-authored-by trailers it adds, so they belong to people even though they contain "noreply".
+const githubUserEmailDomain = "@users.noreply.github.com"
+
+func isBotCoAuthor(coAuthor git.Identity) bool {
+	email := strings.ToLower(coAuthor.Email)
+	if strings.HasSuffix(email, githubUserEmailDomain) {
+		// GitHub Apps get addresses in the same domain, e.g. "49699333+dependabot[bot]@users.noreply.github.com".
+		return strings.Contains(email, "[bot]@") || isBot(coAuthor.Name, "")
+	}
+
+	return isBot(coAuthor.Name, coAuthor.Email)
+}
+
+// filterExclusions filters out excluded names and e-mails.
+func (f *Filter) filterExclusions(commits []git.Commit) []git.Commit {
+	var result []git.Commit
+	for _, commit := range commits {
+		if f.isExcluded(commit.AuthorName, commit.AuthorEmail) {
 			continue
 		}
 
+		commit.CoAuthors = removeIdentities(commit.CoAuthors, func(coAuthor git.Identity) bool {
+			return f.isExcluded(coAuthor.Name, coAuthor.Email)
+		})
 		result = append(result, commit)
 	}
 
 	return result
 }
 
-// filterExclusions filters out excluded names and e-mails.
-func (f *Filter) filterExclusions(commits []git.Commit) []git.Commit {
-	shouldDelete := func(commit git.Commit) bool {
-		for _, f := range f.nameFilters {
-			if f.MatchString(commit.AuthorName) {
-				return true
-			}
+func (f *Filter) isExcluded(name, email string) bool {
+	for _, f := range f.nameFilters {
+		if f.MatchString(name) {
+			return true
 		}
-		for _, f := range f.emailFilters {
-			if f.MatchString(commit.AuthorEmail) {
-				return true
-			}
+	}
+	for _, f := range f.emailFilters {
+		if f.MatchString(email) {
+			return true
 		}
-		return false
 	}
 
-	commits = slices.Clone(commits)
-	return slices.DeleteFunc(commits, shouldDelete)
+	return false
+}
+
+// filterCoAuthored removes commits with co-authors if configured to. It looks at every co-author in the trailers, bots
+// and excluded people included, so it has to run before those are removed.
+func (f *Filter) filterCoAuthored(commits []git.Commit) []git.Commit {
+	if !f.excludeCoAuthored {
+		return commits
+	}
+
+	var result []git.Commit
+	for _, commit := range commits {
+		if !isCoAuthored(commit) {
+			result = append(result, commit)
+		}
+	}
+
+	return result
+}
+
+// isCoAuthored returns whether anyone other than the author is credited on the commit.
+func isCoAuthored(commit git.Commit) bool {
+	for _, coAuthor := range commit.CoAuthors {
+		if !strings.EqualFold(coAuthor.Email, commit.AuthorEmail) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeIdentities returns a copy of identities without the ones that should be removed.
+func removeIdentities(identities []git.Identity, shouldRemove func(git.Identity) bool) []git.Identity {
+	var result []git.Identity
+	for _, identity := range identities {
+		if !shouldRemove(identity) {
+			result = append(result, identity)
+		}
+	}
+
+	return result
 }
 
 func (f *Filter) filterByTeam(commits []git.Commit) []git.Commit {
@@ -147,6 +247,37 @@ func (f *Filter) filterCommitSubjects(commits []git.Commit) []git.Commit {
 	return result
 }
 
+// resolveAliases merges the identities that the config says belong to the same person.
+func (f *Filter) resolveAliases(commits []git.Commit) []git.Commit {
+	result := make([]git.Commit, len(commits))
+	for i, commit := range commits {
+		commit.AuthorName, commit.AuthorEmail = f.resolveAlias(commit.AuthorName, commit.AuthorEmail)
+
+		var coAuthors []git.Identity
+		for _, coAuthor := range commit.CoAuthors {
+			coAuthor.Name, coAuthor.Email = f.resolveAlias(coAuthor.Name, coAuthor.Email)
+			coAuthors = append(coAuthors, coAuthor)
+		}
+		commit.CoAuthors = coAuthors
+
+		result[i] = commit
+	}
+
+	return result
+}
+
+func (f *Filter) resolveAlias(name, email string) (string, string) {
+	canonical, ok := f.aliasesByEmail[strings.ToLower(email)]
+	if !ok {
+		canonical, ok = f.aliasesByName[strings.ToLower(name)]
+	}
+	if !ok {
+		return name, email
+	}
+
+	return cmp.Or(canonical.name, name), canonical.email
+}
+
 // consolidateAuthorDetails ensures that the e-mails and names for all authors are uniform.
 func (f *Filter) consolidateAuthorDetails(commits []git.Commit) []git.Commit {
 	result := make([]git.Commit, len(commits))
@@ -160,6 +291,14 @@ func (f *Filter) consolidateAuthorDetails(commits []git.Commit) []git.Commit {
 		authorEmail := strings.ToLower(commit.AuthorEmail)
 		commit.AuthorEmail = authorEmail
 
+		// Co-authors are lower-cased too. Anyone crediting themself as a co-author is ignored.
+		commit.CoAuthors = removeIdentities(commit.CoAuthors, func(coAuthor git.Identity) bool {
+			return strings.EqualFold(coAuthor.Email, authorEmail)
+		})
+		for j := range commit.CoAuthors {
+			commit.CoAuthors[j].Email = strings.ToLower(commit.CoAuthors[j].Email)
+		}
+
 		if _, ok := emailToAuthorName[authorEmail]; !ok {
 			// This is the first time we have encountered this e-mail.
 			emailToAuthorName[authorEmail] = commit.AuthorName
@@ -2,9 +2,11 @@ package commit
 
 import (
 	"testing"
+	"time"
 
 	"github.com/josephnaberhaus/gauthordle/internal/config"
 	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -38,6 +40,26 @@ func TestFilter_Filter_FilterOutBots(t *testing.T) {
 	assert.Equal(t, expected, filter.filterOutBots(input))
 }
 
+func TestFilter_Filter_FilterOutBotCoAuthors(t *testing.T) {
+	input := []git.Commit{{
+		AuthorName:  "Real person",
+		AuthorEmail: "joe.smith@example.com",
+		CoAuthors: []git.Identity{
+			{Name: "Jane", Email: "1234+jane@users.noreply.github.com"},
+			{Name: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com"},
+			{Name: "Release robot", Email: "5678+releaser@users.noreply.github.com"},
+			{Name: "Builder", Email: "builder@ci.noreply.example.com"},
+		},
+	}}
+
+	filter, err := BuildFilter()
+	require.NoError(t, err)
+
+	result := filter.filterOutBots(input)
+	require.Len(t, result, 1)
+	assert.Equal(t, []git.Identity{{Name: "Jane", Email: "1234+jane@users.noreply.github.com"}}, result[0].CoAuthors)
+}
+
 func TestFilter_Filter_FiltersExclusions(t *testing.T) {
 	input := []git.Commit{
 		{
@@ -190,6 +212,71 @@ func TestFilter_Filter_FilterTeam(t *testing.T) {
 	}
 }
 
+func TestFilter_Filter_ResolvesAliases(t *testing.T) {
+	input := []git.Commit{
+		{
+			AuthorName:  "Joe Smith",
+			AuthorEmail: "joe@work.com",
+		},
+		{
+			AuthorName:  "Joe",
+			AuthorEmail: "JOE@personal.com",
+		},
+		{
+			AuthorName:  "jsmith",
+			AuthorEmail: "jsmith@laptop.local",
+		},
+		{
+			AuthorName:  "Jane Doe",
+			AuthorEmail: "jane@work.com",
+		},
+		{
+			AuthorName:  "Bob",
+			AuthorEmail: "bob@personal.com",
+		},
+	}
+
+	cfg := config.Config{
+		AuthorAliases: []config.AuthorAlias{{
+			Name:   "Joseph Smith",
+			Email:  "joe@work.com",
+			Emails: []string{"joe@personal.com"},
+			Names:  []string{"JSmith"},
+		}, {
+			Email:  "bob@work.com",
+			Emails: []string{"bob@personal.com"},
+		}},
+	}
+
+	expected := []git.Commit{
+		{
+			AuthorName:  "Joseph Smith",
+			AuthorEmail: "joe@work.com",
+		},
+		{
+			AuthorName:  "Joseph Smith",
+			AuthorEmail: "joe@work.com",
+		},
+		{
+			AuthorName:  "Joseph Smith",
+			AuthorEmail: "joe@work.com",
+		},
+		{
+			AuthorName:  "Jane Doe",
+			AuthorEmail: "jane@work.com",
+		},
+		{
+			AuthorName:  "Bob",
+			AuthorEmail: "bob@work.com",
+		},
+	}
+
+	filter, err := BuildFilter(WithConfig(cfg))
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, filter.resolveAliases(input))
+}
+
 func TestFilter_Filter_ConsolidatesAuthorDetails(t *testing.T) {
 	input := []git.Commit{
 		{
@@ -276,3 +363,213 @@ func TestFilter_Filter_FiltersCommitSubjects(t *testing.T) {
 
 	assert.Equal(t, expected, filter.filterCommitSubjects(input))
 }
+
+func TestFilter_Filter_FiltersCoAuthored(t *testing.T) {
+	input := []git.Commit{
+		{
+			AuthorEmail: "joe.smith@example.com",
+			SubjectLine: "solo commit",
+		},
+		{
+			AuthorEmail: "joe.smith@example.com",
+			SubjectLine: "pair programmed commit",
+			CoAuthors:   []git.Identity{{Name: "Jane", Email: "jane@example.com"}},
+		},
+	}
+
+	tests := []struct {
+		desc string
+		cfg  config.Config
+		exp  []git.Commit
+	}{{
+		desc: "co-authors ignored by default",
+		exp:  input,
+	}, {
+		desc: "co-authors accepted",
+		cfg:  config.Config{CoAuthors: config.CoAuthorsAccept},
+		exp:  input,
+	}, {
+		desc: "co-authored commits excluded",
+		cfg:  config.Config{CoAuthors: config.CoAuthorsExclude},
+		exp:  input[:1],
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			filter, err := BuildFilter(WithConfig(tc.cfg))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.exp, filter.filterCoAuthored(input))
+		})
+	}
+
+	t.Run("decided before co-authors are filtered", func(t *testing.T) {
+		day := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+		history := gittest.History{Commits: []git.Commit{{
+			AuthorName:  "Joe",
+			AuthorEmail: "joe.smith@example.com",
+			SubjectLine: "commit crediting myself",
+			CoAuthors:   []git.Identity{{Name: "Joe", Email: "Joe.Smith@example.com"}},
+			CommitTime:  day,
+		}, {
+			AuthorName:  "Joe",
+			AuthorEmail: "joe.smith@example.com",
+			SubjectLine: "commit with a bot",
+			CoAuthors:   []git.Identity{{Name: "Builder", Email: "builder@noreply.example.com"}},
+			CommitTime:  day,
+		}, {
+			AuthorName:  "Joe",
+			AuthorEmail: "joe.smith@example.com",
+			SubjectLine: "commit with someone excluded",
+			CoAuthors:   []git.Identity{{Name: "Contractor", Email: "contractor@example.com"}},
+			CommitTime:  day,
+		}}}
+
+		filter, err := BuildFilter(
+			WithHistory(history),
+			WithStartTime(day),
+			WithEndTime(day),
+			WithConfig(config.Config{
+				CoAuthors:     config.CoAuthorsExclude,
+				AuthorFilters: []config.AuthorFilter{{ExcludeEmail: "contractor@"}},
+			}),
+		)
+		require.NoError(t, err)
+
+		commits, err := filter.GetCommits()
+		require.NoError(t, err)
+		require.Len(t, commits, 1)
+		assert.Equal(t, "commit crediting myself", commits[0].SubjectLine)
+	})
+
+	_, err := BuildFilter(WithConfig(config.Config{CoAuthors: "invalid"}))
+	assert.Error(t, err)
+}
+
+func TestFilter_Filter_RedactsSubjects(t *testing.T) {
+	cfg := config.Config{
+		AuthorAliases: []config.AuthorAlias{{
+			Email:   "joe.naberhaus@work.com",
+			Emails:  []string{"jnaberhaus@personal.com"},
+			Names:   []string{"Joey"},
+			Handles: []string{"@JosephNaberhaus", "jn"},
+		}},
+		RedactPatterns: []string{`\bPAY-\d+\b`},
+	}
+	author := func(subject string) git.Commit {
+		return git.Commit{AuthorName: "Joseph Naberhaus", AuthorEmail: "joe.naberhaus@work.com", SubjectLine: subject}
+	}
+
+	tests := []struct {
+		desc   string
+		commit git.Commit
+		exp    string
+	}{
+		{
+			desc:   "full name",
+			commit: author("Add Joseph Naberhaus to the CODEOWNERS"),
+			exp:    "Add [redacted] to the CODEOWNERS",
+		},
+		{
+			desc:   "part of the name",
+			commit: author("Revert naberhaus's change to the cache"),
+			exp:    "Revert [redacted]'s change to the cache",
+		},
+		{
+			desc:   "e-mail usernames",
+			commit: author("Give joe.naberhaus and jnaberhaus access to prod"),
+			exp:    "Give [redacted] and [redacted] access to prod",
+		},
+		{
+			desc:   "handles and alias names",
+			commit: author("Address review from @josephnaberhaus and Joey"),
+			exp:    "Address review from @[redacted] and [redacted]",
+		},
+		{
+			desc:   "initials used as a branch prefix",
+			commit: author("Land jn/fix-foo into the release branch"),
+			exp:    "Land [redacted]/fix-foo into the release branch",
+		},
+		{
+			desc:   "words that contain the name",
+			commit: author("Rename the Josephine service and json/encoding"),
+			exp:    "Rename the Josephine service and json/encoding",
+		},
+		{
+			desc:   "configured patterns",
+			commit: author("Fix the invoice rounding for PAY-1234"),
+			exp:    "Fix the invoice rounding for [redacted]",
+		},
+		{
+			desc: "co-authors",
+			commit: git.Commit{
+				AuthorName:  "Jane Doe",
+				AuthorEmail: "jane@work.com",
+				SubjectLine: "Pair with Joseph on the jd/zoë-redesign for Zoë",
+				CoAuthors:   []git.Identity{{Name: "Zoë Doe", Email: "zoe@work.com"}},
+			},
+			exp: "Pair with Joseph on the [redacted]/[redacted]-redesign for [redacted]",
+		},
+	}
+
+	filter, err := BuildFilter(WithConfig(cfg))
+	require.NoError(t, err)
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual := filter.redactSubjects([]git.Commit{tc.commit})
+			require.Len(t, actual, 1)
+			assert.Equal(t, tc.exp, actual[0].SubjectLine)
+		})
+	}
+
+	_, err = BuildFilter(WithConfig(config.Config{RedactPatterns: []string{"("}}))
+	assert.ErrorContains(t, err, `invalid redact pattern "("`)
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	var redactor Redactor
+	people := []git.Identity{
+		{Name: "Alice Smith", Email: "asmith@example.com"},
+		{Name: "Bob", Email: "bo@example.com"},
+		{Name: "Carol", Email: "carol@example.com"},
+	}
+
+	assert.Equal(t,
+		"Thanks to [redacted] and [redacted] for the review, see [redacted].\n"+
+			"Ping @[redacted] or [redacted] (not Bobby) on [redacted]'s laptop.\n\n"+
+			"[redacted]\n\n"+
+			"Signed-off-by: [redacted]\nReviewed-by: [redacted]",
+		redactor.Redact(
+			"Thanks to alice smith and Carol for the review, see someone@else.org.\n"+
+				"Ping @asmith or Alice (not Bobby) on Bob's laptop.\n\n"+
+				"-----BEGIN PGP SIGNATURE-----\nabc123\n-----END PGP SIGNATURE-----\n\n"+
+				"Signed-off-by: Alice Smith <asmith@example.com>\nReviewed-by: Dave <dave@example.com>",
+			people,
+		),
+	)
+	// Names with letters outside of ASCII are redacted too, but not inside larger words.
+	assert.Equal(t, "Thanks [redacted] and [redacted], not Zoëtrope", redactor.Redact(
+		"Thanks Zoë and Ørsted, not Zoëtrope",
+		[]git.Identity{{Name: "Zoë", Email: "zoe@example.com"}, {Name: "Ørsted", Email: "hco@example.com"}},
+	))
+	// Names and e-mail usernames that are too short aren't redacted.
+	assert.Equal(t, "bo and a/main.go", redactor.Redact("bo and a/main.go", []git.Identity{{Name: "A", Email: "bo@example.com"}}))
+	assert.Equal(t, "nothing to hide", redactor.Redact("nothing to hide", nil))
+	// Versions aren't e-mails, and trailers can't be on the subject line.
+	assert.Equal(t, "Bump react@18.2.0 and lodash@4.17.21 for [redacted]", redactor.Redact(
+		"Bump react@18.2.0 and lodash@4.17.21 for ops@example.co.uk", nil,
+	))
+	assert.Equal(t, "Sort-by: name in the table", redactor.Redact("Sort-by: name in the table", nil))
+
+	// The config applies to all text, not only subjects.
+	filter, err := BuildFilter(WithConfig(config.Config{
+		AuthorAliases:  []config.AuthorAlias{{Email: "joe@work.com", Handles: []string{"jn"}}},
+		RedactPatterns: []string{`\bPAY-\d+\b`},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t,
+		"Fixes [redacted].\n\n--- a/docs/[redacted]/notes.md",
+		filter.Redactor().Redact("Fixes PAY-1234.\n\n--- a/docs/jn/notes.md", []git.Identity{{Name: "Joseph", Email: "joe@work.com"}}),
+	)
+}
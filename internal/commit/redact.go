@@ -0,0 +1,229 @@
+package commit
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+)
+
+// redacted replaces the parts of commits that would give away who made them.
+const redacted = "[redacted]"
+
+// minRedactedLength is the shortest name, part of a name, or e-mail username that's redacted. Shorter ones would
+// redact too many unrelated words. Configured handles are always redacted.
+const minRedactedLength = 3
+
+var (
+	// signaturePattern matches PGP and SSH signatures.
+	signaturePattern = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*SIGNATURE-----.*?-----END [A-Z ]*SIGNATURE-----`)
+	// creditTrailerPattern matches trailers that credit someone, like "Signed-off-by" and "Reviewed-by".
+	creditTrailerPattern = regexp.MustCompile(`(?im)^([a-z0-9-]+-by)[ \t]*:.*$`)
+	// emailPattern matches e-mails. The top-level domain must be letters so that versions like "react@18.2.0" aren't
+	// mistaken for e-mails.
+	emailPattern = regexp.MustCompile(`[\w.%+-]+@[\w-]+(\.[\w-]+)*\.[A-Za-z]{2,}\b`)
+)
+
+// Redactor masks the text of commits that would give people away. The same redactor is used for the subjects, bodies,
+// patches, and stats of commits so that they're all redacted the same way. The zero value redacts without any config.
+type Redactor struct {
+	// aliasesByEmail and aliasesByName are shared with the filter. See Filter.
+	aliasesByEmail map[string]identity
+	aliasesByName  map[string]identity
+	// handlesByEmail is a map from a lower-cased canonical e-mail to the person's handles.
+	handlesByEmail map[string][]string
+	// patterns are redacted from all text.
+	patterns []*regexp.Regexp
+
+	mu sync.Mutex
+	// patternsByEmail caches the patterns of each person, by lower-cased e-mail.
+	patternsByEmail map[string]identityPatterns
+}
+
+// identityPatterns match the text that identifies one person.
+type identityPatterns struct {
+	// words matches the person's names, e-mail usernames, and handles. It's nil if there aren't any.
+	words *regexp.Regexp
+	// branchPrefix matches the person's initials used as a branch prefix, like "jn/" in "Merge jn/fix-foo".
+	// It's nil if the person doesn't have initials.
+	branchPrefix *regexp.Regexp
+}
+
+// Redactor returns the redactor that the subjects are redacted with, so that the rest of the commits can be redacted
+// the same way.
+func (f *Filter) Redactor() *Redactor {
+	return f.redactor
+}
+
+// redactSubjects masks anything in the commit subjects that identifies the author or co-authors. This must run after
+// the authors are consolidated so that every commit by a person is redacted the same way.
+func (f *Filter) redactSubjects(commits []git.Commit) []git.Commit {
+	result := make([]git.Commit, len(commits))
+	for i, commit := range commits {
+		people := append([]git.Identity{{Name: commit.AuthorName, Email: commit.AuthorEmail}}, commit.CoAuthors...)
+		commit.SubjectLine = f.redactor.Redact(commit.SubjectLine, people)
+		result[i] = commit
+	}
+
+	return result
+}
+
+// Redact replaces signatures, the values of trailers that credit someone, e-mails, the configured patterns, and
+// anything that identifies the given people, like their names, usernames, handles, and initials used as a branch
+// prefix. It's safe to call concurrently.
+func (r *Redactor) Redact(text string, people []git.Identity) string {
+	text = signaturePattern.ReplaceAllString(text, redacted)
+	// Trailers are only at the end of a message, so a subject like "Sort-by: name in the table" is left alone.
+	if strings.Contains(text, "\n") {
+		text = creditTrailerPattern.ReplaceAllString(text, "$1: "+redacted)
+	}
+	text = emailPattern.ReplaceAllString(text, redacted)
+	for _, pattern := range r.patterns {
+		text = pattern.ReplaceAllString(text, redacted)
+	}
+
+	for _, person := range people {
+		patterns := r.identityPatterns(person)
+		text = replaceWords(patterns.words, text, redacted)
+		text = replaceWords(patterns.branchPrefix, text, redacted+"/")
+	}
+
+	return text
+}
+
+// identityPatterns returns the cached patterns for the person, building them if needed.
+func (r *Redactor) identityPatterns(person git.Identity) identityPatterns {
+	email := strings.ToLower(person.Email)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if result, ok := r.patternsByEmail[email]; ok {
+		return result
+	}
+	if r.patternsByEmail == nil {
+		r.patternsByEmail = map[string]identityPatterns{}
+	}
+
+	result := r.buildIdentityPatterns(person.Name, email)
+	r.patternsByEmail[email] = result
+	return result
+}
+
+// buildIdentityPatterns builds the patterns for the person with the given name and lower-cased e-mail. Any names and
+// e-mails that the config says the person has also committed with are included.
+func (r *Redactor) buildIdentityPatterns(name, email string) identityPatterns {
+	names := []string{name}
+	emails := []string{email}
+	for aliasEmail, canonical := range r.aliasesByEmail {
+		if canonical.email == email && aliasEmail != email {
+			emails = append(emails, aliasEmail)
+		}
+	}
+	for aliasName, canonical := range r.aliasesByName {
+		if canonical.email == email {
+			names = append(names, aliasName)
+		}
+	}
+
+	seen := map[string]struct{}{}
+	var words []string
+	add := func(word string, minLength int) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if utf8.RuneCountInString(word) < minLength {
+			return
+		}
+		if _, ok := seen[word]; ok {
+			return
+		}
+		seen[word] = struct{}{}
+		words = append(words, word)
+	}
+	var initials []string
+	for _, name := range names {
+		add(name, minRedactedLength)
+		// People are often mentioned by only their first or last name.
+		parts := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) })
+		for _, part := range parts {
+			add(part, minRedactedLength)
+		}
+		if len(parts) > 1 {
+			var nameInitials strings.Builder
+			for _, part := range parts {
+				first, _ := utf8.DecodeRuneInString(part)
+				nameInitials.WriteRune(unicode.ToLower(first))
+			}
+			initials = append(initials, nameInitials.String())
+		}
+	}
+	for _, email := range emails {
+		if username, _, ok := strings.Cut(email, "@"); ok {
+			add(username, minRedactedLength)
+		}
+	}
+	for _, handle := range r.handlesByEmail[email] {
+		add(strings.TrimPrefix(handle, "@"), 1)
+	}
+
+	return identityPatterns{
+		words:        wordsPattern(words, ""),
+		branchPrefix: wordsPattern(initials, "/"),
+	}
+}
+
+// wordsPattern compiles a case-insensitive pattern matching any of the words followed by the suffix. The longest words
+// are matched first so that a full name is redacted rather than only part of it. It returns nil if there aren't any
+// words.
+func wordsPattern(words []string, suffix string) *regexp.Regexp {
+	if len(words) == 0 {
+		return nil
+	}
+
+	words = slices.Clone(words)
+	slices.SortFunc(words, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(words, "|") + `)` + regexp.QuoteMeta(suffix))
+}
+
+// replaceWords replaces the matches of the pattern that aren't part of a larger word, like "bob" in "bobsled".
+// Unlike \b in a pattern, this works for words that start or end with letters outside of ASCII. A nil pattern matches
+// nothing.
+func replaceWords(pattern *regexp.Regexp, s, replacement string) string {
+	if pattern == nil {
+		return s
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(s, -1) {
+		start, end := match[0], match[1]
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		first, _ := utf8.DecodeRuneInString(s[start:end])
+		lastInMatch, _ := utf8.DecodeLastRuneInString(s[start:end])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if (isWordRune(before) && isWordRune(first)) || (isWordRune(lastInMatch) && isWordRune(after)) {
+			continue
+		}
+
+		result.WriteString(s[last:start])
+		result.WriteString(replacement)
+		last = end
+	}
+	result.WriteString(s[last:])
+
+	return result.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
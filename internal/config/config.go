@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,13 +18,102 @@ type AuthorFilter struct {
 	ExcludeEmail string `yaml:"exclude_email"`
 }
 
+// AuthorAlias merges all the identities a person has committed with into one.
+type AuthorAlias struct {
+	// Name is the name to show for the person. If empty, the most recently used name is shown.
+	Name string `yaml:"name"`
+	// Email is the person's canonical e-mail.
+	Email string `yaml:"email"`
+	// Emails are other e-mails the person has committed with.
+	Emails []string `yaml:"emails"`
+	// Names are other names the person has committed with. Any commit with one of these names is merged into this person.
+	Names []string `yaml:"names"`
+	// Handles are the person's usernames on other sites, like GitHub, which are redacted from their commit subjects.
+	Handles []string `yaml:"handles"`
+}
+
+// How commits with "Co-authored-by" trailers are treated.
+const (
+	// CoAuthorsIgnore only credits the commit's author.
+	CoAuthorsIgnore = "ignore"
+	// CoAuthorsExclude removes commits with co-authors from the game.
+	CoAuthorsExclude = "exclude"
+	// CoAuthorsAccept accepts guessing any of the commit's co-authors as the correct answer.
+	CoAuthorsAccept = "accept"
+)
+
+// Hint chooses when a hint is revealed.
+type Hint struct {
+	// Name is the name of the hint. See game.HintNames for the available hints.
+	Name string `yaml:"name"`
+	// Stage is the 1-indexed stage that the hint is revealed at.
+	Stage int `yaml:"stage"`
+}
+
+// Detail chooses when a detail of the game's commits, like their bodies, is revealed.
+type Detail struct {
+	// Name is the name of the detail. One of "body", "stat", or "patch".
+	Name string `yaml:"name"`
+	// Stage is the 1-indexed stage that the detail is revealed at.
+	Stage int `yaml:"stage"`
+}
+
+// Formats of the payloads posted to webhooks.
+const (
+	// WebhookFormatJSON posts a JSON object describing the event.
+	WebhookFormatJSON = "json"
+	// WebhookFormatSlack posts a message in the format of Slack's incoming webhooks.
+	WebhookFormatSlack = "slack"
+)
+
+// Webhook is a URL that game results are posted to.
+type Webhook struct {
+	URL string `yaml:"url"`
+	// Format is the format of the payload. One of the WebhookFormat constants. Defaults to WebhookFormatJSON.
+	Format string `yaml:"format"`
+}
+
 type Config struct {
 	// AuthorFilters are filters that will remove the specified authors from the game.
 	AuthorFilters []AuthorFilter `yaml:"author_filters"`
+	// AuthorAliases merge authors that have committed with multiple identities.
+	AuthorAliases []AuthorAlias `yaml:"author_aliases"`
+	// RedactPatterns are regular expressions that are redacted from every commit subject, like issue keys that are
+	// tied to one person.
+	RedactPatterns []string `yaml:"redact_patterns"`
 	// Teams is a map from team name to the members of that team.
 	Teams map[string]Team `yaml:"teams"`
 	// AuthorBias is how much to bias towards authors with high commit counts.
 	AuthorBias *float64 `yaml:"author_bias"`
+	// CoAuthors is how commits with co-authors are treated. One of the CoAuthors constants. Defaults to CoAuthorsIgnore.
+	CoAuthors string `yaml:"co_authors"`
+	// Timezone is the IANA name of the time zone that daily games are scheduled in. Defaults to UTC.
+	Timezone string `yaml:"timezone"`
+	// RolloverHour is the hour of the day in Timezone that new daily games start. Defaults to midnight.
+	RolloverHour int `yaml:"rollover_hour"`
+	// Repos are the paths of the repositories to build games from. Relative paths are relative to the home directory.
+	// If empty, the repository in the working directory is used.
+	Repos []string `yaml:"repos"`
+	// DefaultRef is the revision that games are built from, e.g. "origin/main". Defaults to HEAD.
+	DefaultRef string `yaml:"default_ref"`
+	// WindowStart is the oldest commits that games are built from. See game.ParseWindowBound for the format.
+	WindowStart string `yaml:"window_start"`
+	// WindowEnd is the newest commits that games are built from. See game.ParseWindowBound for the format.
+	WindowEnd string `yaml:"window_end"`
+	// PuzzleLength is the number of commits shown in each game, which is also the number of guesses. Defaults to 4.
+	PuzzleLength int `yaml:"puzzle_length"`
+	// Difficulty is the order that commits are revealed in. Either "easy", "normal" (the default), or "hard".
+	Difficulty string `yaml:"difficulty"`
+	// Hints are the hints revealed during the game and when. If empty, the default hints are used.
+	Hints []Hint `yaml:"hints"`
+	// Details are the details of the commits revealed during the game and when. By default, only subjects are shown.
+	Details []Detail `yaml:"details"`
+	// Player is the name that results are submitted to a leaderboard with.
+	Player string `yaml:"player"`
+	// Webhooks are posted the result of every daily game played and the announcements of the answers.
+	Webhooks []Webhook `yaml:"webhooks"`
+	// HistoryBackend is how the git history is read. Either "native" (the default) or "git" to run the git binary.
+	HistoryBackend string `yaml:"history_backend"`
 }
 
 func Load() (Config, error) {
@@ -51,3 +141,23 @@ func Load() (Config, error) {
 
 	return cfg, nil
 }
+
+// RepoDirs returns the directories of the Repos. Relative paths, including ones starting with "~/", are resolved
+// against the home directory, which is where the config file is.
+func (c Config) RepoDirs() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, dir := range c.Repos {
+		dir = strings.TrimPrefix(dir, "~/")
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(home, dir)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	return dirs, nil
+}
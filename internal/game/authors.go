@@ -47,24 +47,26 @@ func allAuthorEmails(commits []git.Commit) []string {
 	for author := range authorSet {
 		result = append(result, author)
 	}
+	// Sort so that the result doesn't depend on map iteration order.
+	slices.Sort(result)
 
 	return result
 }
 
-func pickAuthor(commits []git.Commit, authorBias float64, random *rand.Rand) (string, error) {
+func pickAuthor(commits []git.Commit, numCommits int, authorBias float64, random *rand.Rand) (string, error) {
 	numByAuthor := numCommitsByAuthorEmail(commits)
 	allAuthors := allAuthorEmails(commits)
 
 	// Filter out authors that have made fewer commits than the number we need for the puzzle.
 	allAuthors = slices.DeleteFunc(allAuthors, func(s string) bool {
-		if numByAuthor[s] < numPuzzleCommits {
+		if numByAuthor[s] < numCommits {
 			return true
 		}
 
 		return false
 	})
 	if len(allAuthors) == 0 {
-		return "", fmt.Errorf("there are no authors with %d or more valid commits", numPuzzleCommits)
+		return "", fmt.Errorf("there are no authors with %d or more valid commits", numCommits)
 	}
 
 	// Sort the authors by how many commits they've made.
@@ -90,23 +92,13 @@ func pickAuthor(commits []git.Commit, authorBias float64, random *rand.Rand) (st
 	return allAuthors[index], nil
 }
 
-func mostTouchedFileForAuthor(authorEmail string) (string, error) {
-	filesChanged, err := git.GetFilesChangedForAuthor(authorEmail)
-	if err != nil {
-		return "", fmt.Errorf("error while getting the author's most touched file: %w", err)
-	}
-
-	fileCount := map[string]int{}
-	for _, file := range filesChanged {
-		fileCount[file]++
-	}
-
-	maxCountFile := ""
-	for file, count := range fileCount {
-		if count > fileCount[maxCountFile] {
-			maxCountFile = file
+// isMultiRepository returns whether the commits come from more than one repository.
+func isMultiRepository(commits []git.Commit) bool {
+	for _, commit := range commits {
+		if commit.Repository != commits[0].Repository {
+			return true
 		}
 	}
 
-	return maxCountFile, nil
+	return false
 }
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
+	"github.com/josephnaberhaus/gauthordle/internal/commit"
 	"github.com/josephnaberhaus/gauthordle/internal/git"
 )
 
@@ -13,6 +15,14 @@ type builder struct {
 	randomSource rand.Source
 	commits      []git.Commit
 	authorBias   float64
+	// acceptCoAuthors specifies whether guessing a co-author of a revealed commit is correct.
+	acceptCoAuthors bool
+	hints           []HintStage
+	numCommits      int
+	difficulty      Difficulty
+	details         []DetailStage
+	history         git.History
+	redactor        *commit.Redactor
 }
 
 type Option func(*builder)
@@ -35,6 +45,59 @@ func WithAuthorBias(authorBias float64) Option {
 	}
 }
 
+func WithCoAuthorsAccepted(acceptCoAuthors bool) Option {
+	return func(b *builder) {
+		b.acceptCoAuthors = acceptCoAuthors
+	}
+}
+
+// WithNumCommits sets the number of commits shown in the puzzle, which is also the number of guesses. Defaults to
+// DefaultNumCommits.
+func WithNumCommits(numCommits int) Option {
+	return func(b *builder) {
+		b.numCommits = numCommits
+	}
+}
+
+// WithDifficulty sets the order that the commits are revealed in. Defaults to DifficultyNormal.
+func WithDifficulty(difficulty Difficulty) Option {
+	return func(b *builder) {
+		b.difficulty = difficulty
+	}
+}
+
+// WithHints chooses the hints that are revealed and when. Defaults to DefaultHints.
+func WithHints(hints []HintStage) Option {
+	return func(b *builder) {
+		b.hints = hints
+	}
+}
+
+// WithDetails chooses the details of the puzzle's commits, like their bodies, that are revealed and when. By default,
+// only the subjects are revealed.
+func WithDetails(details []DetailStage) Option {
+	return func(b *builder) {
+		b.details = details
+	}
+}
+
+// WithHistory sets the history that the commits are from. The details of the puzzle's commits are read from it, which
+// is required to reveal their bodies or patches.
+func WithHistory(history git.History) Option {
+	return func(b *builder) {
+		b.history = history
+	}
+}
+
+// WithRedactor sets the redactor that the details of the puzzle's commits are redacted with. It should be the one that
+// the commits' subjects were redacted with, so that the config is applied to the details too. By default, the details
+// are redacted without any config.
+func WithRedactor(redactor *commit.Redactor) Option {
+	return func(b *builder) {
+		b.redactor = redactor
+	}
+}
+
 func BuildPuzzle(opts ...Option) (Puzzle, error) {
 	b := new(builder)
 	for _, opt := range opts {
@@ -48,6 +111,23 @@ func BuildPuzzle(opts ...Option) (Puzzle, error) {
 	if b.authorBias < 1 || b.authorBias > 5 {
 		return Puzzle{}, errors.New("author bias must be between 1 and 5")
 	}
+	if b.numCommits == 0 {
+		b.numCommits = DefaultNumCommits
+	}
+	if b.numCommits < 1 {
+		return Puzzle{}, errors.New("the number of commits must be at least 1")
+	}
+	if b.hints == nil {
+		b.hints = DefaultHints(b.numCommits)
+	}
+	if b.redactor == nil {
+		b.redactor = new(commit.Redactor)
+	}
+	difficulty, err := ParseDifficulty(string(b.difficulty))
+	if err != nil {
+		return Puzzle{}, err
+	}
+	b.difficulty = difficulty
 
 	return b.buildPuzzle()
 }
@@ -55,7 +135,7 @@ func BuildPuzzle(opts ...Option) (Puzzle, error) {
 func (b builder) buildPuzzle() (Puzzle, error) {
 	random := rand.New(b.randomSource)
 
-	author, err := pickAuthor(b.commits, b.authorBias, random)
+	author, err := pickAuthor(b.commits, b.numCommits, b.authorBias, random)
 	if err != nil {
 		return Puzzle{}, fmt.Errorf("error building puzzle: %w", err)
 	}
@@ -63,29 +143,41 @@ func (b builder) buildPuzzle() (Puzzle, error) {
 	authorNames := nameByEmail(b.commits)
 	commitsByAuthor := commitsByAuthorEmail(b.commits)
 
-	mostTouchedFile, err := mostTouchedFileForAuthor(author)
+	hints, err := scheduleHints(b.hints, b.numCommits, commitsByAuthor[author], isMultiRepository(b.commits))
+	if err != nil {
+		return Puzzle{}, fmt.Errorf("error building puzzle: %w", err)
+	}
+
+	puzzleCommits := pickPuzzleCommits(commitsByAuthor[author], b.numCommits, random)
+	orderByDifficulty(puzzleCommits, b.difficulty, b.commits)
+
+	// Anyone could be mentioned in the details, not only the author, so everyone in the history is redacted.
+	people := identities(b.commits)
+	redact := func(text string) string {
+		return b.redactor.Redact(text, people)
+	}
+	details, err := readDetails(b.details, b.numCommits, puzzleCommits, b.history, redact)
 	if err != nil {
 		return Puzzle{}, fmt.Errorf("error building puzzle: %w", err)
 	}
 
 	return Puzzle{
-		authorEmail:   author,
-		authorName:    authorNames[author],
-		authorCommits: commitsByAuthor[author],
-		puzzleCommits: pickPuzzleCommits(commitsByAuthor[author], random),
-		hints: puzzleHints{
-			totalCommits:    len(commitsByAuthor[author]),
-			mostTouchedFile: mostTouchedFile,
-		},
-		allCommits:     b.commits,
-		allAuthorNames: authorNames,
+		authorEmail:     author,
+		authorName:      authorNames[author],
+		authorCommits:   commitsByAuthor[author],
+		puzzleCommits:   puzzleCommits,
+		hints:           hints,
+		details:         details,
+		allCommits:      b.commits,
+		allAuthorNames:  authorNames,
+		acceptCoAuthors: b.acceptCoAuthors,
 	}, nil
 }
 
-func pickPuzzleCommits(authorCommits []git.Commit, random *rand.Rand) [numPuzzleCommits]git.Commit {
-	var result [numPuzzleCommits]git.Commit
+func pickPuzzleCommits(authorCommits []git.Commit, numCommits int, random *rand.Rand) []git.Commit {
+	result := make([]git.Commit, numCommits)
 	pickedIndices := map[int]struct{}{}
-	for i := 0; i < numPuzzleCommits; i++ {
+	for i := 0; i < numCommits; i++ {
 		index := random.Intn(len(authorCommits))
 		if _, ok := pickedIndices[index]; ok {
 			// We've already picked this number. Try again.
@@ -99,3 +191,26 @@ func pickPuzzleCommits(authorCommits []git.Commit, random *rand.Rand) [numPuzzle
 
 	return result
 }
+
+// identities returns every author and co-author of the commits, once each.
+func identities(commits []git.Commit) []git.Identity {
+	seen := map[string]struct{}{}
+	var result []git.Identity
+	add := func(identity git.Identity) {
+		email := strings.ToLower(identity.Email)
+		if _, ok := seen[email]; ok {
+			return
+		}
+		seen[email] = struct{}{}
+		result = append(result, identity)
+	}
+
+	for _, c := range commits {
+		add(git.Identity{Name: c.AuthorName, Email: c.AuthorEmail})
+		for _, coAuthor := range c.CoAuthors {
+			add(coAuthor)
+		}
+	}
+
+	return result
+}
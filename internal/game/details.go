@@ -0,0 +1,195 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+)
+
+// The details of the puzzle's commits that can be revealed, by name.
+const (
+	// DetailBody reveals the commit message after the subject.
+	DetailBody = "body"
+	// DetailStat reveals a summary of the files the commit changed, like "git diff --stat".
+	DetailStat = "stat"
+	// DetailPatch reveals the first lines the commit changed.
+	DetailPatch = "patch"
+)
+
+// maxStatFiles is the most files listed in a stat. The rest are counted in a single line.
+const maxStatFiles = 5
+
+// statGraphWidth is the widest that the graph of a file's changes in a stat gets.
+const statGraphWidth = 20
+
+// maxPatchLines is the most lines of a patch that are revealed.
+const maxPatchLines = 12
+
+// DetailStage chooses when a detail of the puzzle's commits is revealed.
+type DetailStage struct {
+	// Name is the name of the detail. One of the Detail constants.
+	Name string
+	// Stage is the 1-indexed stage that the detail is revealed at.
+	Stage int
+}
+
+// ParseDetails parses a comma separated list of details and the 1-indexed stages they're revealed at, e.g.
+// "body:3,stat:4".
+func ParseDetails(s string) ([]DetailStage, error) {
+	return parseStages(s, "detail", func(name string, stage int) DetailStage {
+		return DetailStage{Name: name, Stage: stage}
+	})
+}
+
+// puzzleDetails are the revealable details of the puzzle's commits.
+type puzzleDetails struct {
+	// stages are the 0-indexed stages that the details are revealed at, by name. Details that are never revealed
+	// aren't included.
+	stages map[string]int
+	// commits are the redacted details of each of the puzzle's commits, in the same order as the commits.
+	commits []commitDetails
+}
+
+type commitDetails struct {
+	body  string
+	stat  string
+	patch string
+}
+
+// revealed returns whether the detail is revealed at the given 0-indexed stage.
+func (d puzzleDetails) revealed(name string, stage int) bool {
+	revealStage, ok := d.stages[name]
+	return ok && stage >= revealStage
+}
+
+// readDetails reads the details of the puzzle's commits that are revealed and redacts them with redact. Only the body
+// and patch have to be read from the history.
+func readDetails(stages []DetailStage, numStages int, puzzleCommits []git.Commit, history git.History, redact func(string) string) (puzzleDetails, error) {
+	result := puzzleDetails{stages: map[string]int{}}
+	for _, detailStage := range stages {
+		switch detailStage.Name {
+		case DetailBody, DetailStat, DetailPatch:
+		default:
+			return puzzleDetails{}, fmt.Errorf("unknown detail %q, expected one of %q, %q, or %q", detailStage.Name, DetailBody, DetailStat, DetailPatch)
+		}
+		if _, ok := result.stages[detailStage.Name]; ok {
+			return puzzleDetails{}, fmt.Errorf("detail %q is listed more than once", detailStage.Name)
+		}
+		if detailStage.Stage < 1 || detailStage.Stage > numStages {
+			return puzzleDetails{}, fmt.Errorf("detail %q has stage %d, but it must be between 1 and %d", detailStage.Name, detailStage.Stage, numStages)
+		}
+		result.stages[detailStage.Name] = detailStage.Stage - 1
+	}
+	if len(result.stages) == 0 {
+		return result, nil
+	}
+
+	_, showBody := result.stages[DetailBody]
+	_, showPatch := result.stages[DetailPatch]
+	if (showBody || showPatch) && history == nil {
+		return puzzleDetails{}, errors.New("revealing the body or patch of commits requires a history")
+	}
+
+	for _, commit := range puzzleCommits {
+		var details commitDetails
+		if showBody || showPatch {
+			gitDetails, err := history.CommitDetails(commit)
+			if err != nil {
+				return puzzleDetails{}, fmt.Errorf("error when reading the details of commit %s: %w", commit.Hash, err)
+			}
+
+			if showBody {
+				details.body = redact(gitDetails.Body)
+			}
+			if showPatch {
+				details.patch = truncatePatch(redact(gitDetails.Patch))
+			}
+		}
+		if _, ok := result.stages[DetailStat]; ok {
+			// Paths can name people too, like "docs/jdoe/notes.md".
+			files := make([]git.FileChange, len(commit.Files))
+			for i, file := range commit.Files {
+				file.Path = redact(file.Path)
+				files[i] = file
+			}
+			details.stat = formatStat(files)
+		}
+
+		result.commits = append(result.commits, details)
+	}
+
+	return result, nil
+}
+
+// truncatePatch cuts the patch down to maxPatchLines lines.
+func truncatePatch(patch string) string {
+	lines := strings.Split(strings.TrimSuffix(patch, "\n"), "\n")
+	if len(lines) <= maxPatchLines {
+		return strings.Join(lines, "\n")
+	}
+
+	return strings.Join(lines[:maxPatchLines], "\n") + fmt.Sprintf("\n... %d more lines", len(lines)-maxPatchLines)
+}
+
+// formatStat summarizes the changed files like "git diff --stat", e.g.:
+//
+//	main.go | 12 ++++++++----
+//	 1 file changed, 8 insertions(+), 4 deletions(-)
+func formatStat(files []git.FileChange) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var totalAdditions, totalDeletions, maxChanges, pathWidth, countWidth int
+	for _, file := range files[:min(len(files), maxStatFiles)] {
+		maxChanges = max(maxChanges, file.Additions+file.Deletions)
+		pathWidth = max(pathWidth, len(file.Path))
+		countWidth = max(countWidth, len(strconv.Itoa(file.Additions+file.Deletions)))
+	}
+	for _, file := range files {
+		totalAdditions += file.Additions
+		totalDeletions += file.Deletions
+	}
+
+	// Scale the graphs down to fit, but keep at least one mark for any changes.
+	scale := func(n int) int {
+		if n == 0 || maxChanges <= statGraphWidth {
+			return n
+		}
+		return max(1, n*statGraphWidth/maxChanges)
+	}
+
+	var result strings.Builder
+	for _, file := range files[:min(len(files), maxStatFiles)] {
+		fmt.Fprintf(&result, "%-*s | ", pathWidth, file.Path)
+		if file.Binary {
+			result.WriteString("Bin\n")
+			continue
+		}
+
+		fmt.Fprintf(&result, "%*d %s%s\n", countWidth, file.Additions+file.Deletions,
+			strings.Repeat("+", scale(file.Additions)), strings.Repeat("-", scale(file.Deletions)))
+	}
+	if len(files) > maxStatFiles {
+		fmt.Fprintf(&result, "... %d more files\n", len(files)-maxStatFiles)
+	}
+
+	fmt.Fprintf(&result, " %s changed, %s, %s",
+		pluralize(len(files), "file", "files"),
+		pluralize(totalAdditions, "insertion(+)", "insertions(+)"),
+		pluralize(totalDeletions, "deletion(-)", "deletions(-)"),
+	)
+
+	return result.String()
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+
+	return strconv.Itoa(n) + " " + plural
+}
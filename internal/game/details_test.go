@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/josephnaberhaus/gauthordle/internal/commit"
+	"github.com/josephnaberhaus/gauthordle/internal/config"
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatStat(t *testing.T) {
+	assert.Equal(t,
+		// The graphs are scaled down to fit.
+		"internal/server.go | 30 ++++++++++++++++---\n"+
+			"README             |  2 +\n"+
+			"logo.png           | Bin\n"+
+			" 3 files changed, 27 insertions(+), 5 deletions(-)",
+		formatStat([]git.FileChange{
+			{Path: "internal/server.go", Additions: 25, Deletions: 5},
+			{Path: "README", Additions: 2},
+			{Path: "logo.png", Binary: true},
+		}),
+	)
+
+	var files []git.FileChange
+	for i := range 7 {
+		files = append(files, git.FileChange{Path: fmt.Sprintf("file%d", i), Additions: 1})
+	}
+	stat := formatStat(files)
+	assert.Contains(t, stat, "file4 | 1 +\n... 2 more files\n")
+	assert.True(t, strings.HasSuffix(stat, " 7 files changed, 7 insertions(+), 0 deletions(-)"), stat)
+
+	assert.Empty(t, formatStat(nil))
+}
+
+func TestTruncatePatch(t *testing.T) {
+	assert.Equal(t, "--- a/main.go\n+++ b/main.go\n+hello", truncatePatch("--- a/main.go\n+++ b/main.go\n+hello\n"))
+
+	var patch strings.Builder
+	for i := range maxPatchLines + 3 {
+		fmt.Fprintf(&patch, "+line %d\n", i)
+	}
+	truncated := truncatePatch(patch.String())
+	assert.True(t, strings.HasSuffix(truncated, fmt.Sprintf("+line %d\n... 3 more lines", maxPatchLines-1)), truncated)
+}
+
+func TestParseDetails(t *testing.T) {
+	details, err := ParseDetails("body:3, stat:4")
+	require.NoError(t, err)
+	assert.Equal(t, []DetailStage{{Name: DetailBody, Stage: 3}, {Name: DetailStat, Stage: 4}}, details)
+
+	_, err = ParseDetails("body")
+	assert.EqualError(t, err, `invalid detail "body", expected <name>:<stage>`)
+}
+
+func TestBuildPuzzle_Details(t *testing.T) {
+	var commits []git.Commit
+	history := gittest.History{Details: map[string]git.CommitDetails{}}
+	for i := range 4 {
+		hash := fmt.Sprint("alice", i)
+		commits = append(commits, git.Commit{
+			Hash:        hash,
+			AuthorName:  "Alice",
+			AuthorEmail: "alice@example.com",
+			SubjectLine: fmt.Sprint("Change number ", i),
+			Files:       []git.FileChange{{Path: "main.go", Additions: 1}, {Path: "docs/alice/notes.md", Additions: 2}},
+		})
+		history.Details[hash] = git.CommitDetails{
+			Body:  "Written by Alice.\n\nSigned-off-by: Alice <alice@example.com>",
+			Patch: "--- a/main.go\n+++ b/main.go\n+// Maintained by alice@example.com\n",
+		}
+	}
+
+	build := func(details []DetailStage, history git.History, opts ...Option) (Puzzle, error) {
+		return BuildPuzzle(append([]Option{
+			WithCommits(commits),
+			WithRandomSource(rand.NewSource(1)),
+			WithAuthorBias(1),
+			WithHistory(history),
+			WithDetails(details),
+		}, opts...)...)
+	}
+
+	puzzle, err := build([]DetailStage{{Name: DetailBody, Stage: 2}, {Name: DetailStat, Stage: 3}, {Name: DetailPatch, Stage: 4}}, history)
+	require.NoError(t, err)
+
+	assert.Equal(t, Clue{Subject: puzzle.puzzleCommits[0].SubjectLine}, puzzle.Clues(0)[0])
+	assert.Equal(t, "Written by [redacted].\n\nSigned-off-by: [redacted]", puzzle.Clues(1)[0].Body)
+	assert.Empty(t, puzzle.Clues(1)[1].Stat)
+	assert.Equal(t,
+		"main.go                  | 1 +\n"+
+			"docs/[redacted]/notes.md | 2 ++\n"+
+			" 2 files changed, 3 insertions(+), 0 deletions(-)",
+		puzzle.Clues(2)[1].Stat,
+	)
+	assert.Empty(t, puzzle.Clues(2)[2].Patch)
+	// Details are revealed for every commit shown at the stage, not only the ones shown before.
+	assert.Equal(t, "--- a/main.go\n+++ b/main.go\n+// Maintained by [redacted]", puzzle.Clues(3)[3].Patch)
+
+	// The config that the subjects were redacted with applies to the details too.
+	filter, err := commit.BuildFilter(commit.WithConfig(config.Config{RedactPatterns: []string{`Written`}}))
+	require.NoError(t, err)
+	puzzle, err = build([]DetailStage{{Name: DetailBody, Stage: 1}}, history, WithRedactor(filter.Redactor()))
+	require.NoError(t, err)
+	assert.Equal(t, "[redacted] by [redacted].\n\nSigned-off-by: [redacted]", puzzle.Clues(0)[0].Body)
+
+	// The stat doesn't need to be read from the history.
+	_, err = build([]DetailStage{{Name: DetailStat, Stage: 1}}, nil)
+	assert.NoError(t, err)
+	_, err = build([]DetailStage{{Name: DetailBody, Stage: 1}}, nil)
+	assert.EqualError(t, err, "error building puzzle: revealing the body or patch of commits requires a history")
+	_, err = build([]DetailStage{{Name: DetailPatch, Stage: 1}}, gittest.History{})
+	assert.ErrorContains(t, err, "error when reading the details of commit alice")
+	_, err = build([]DetailStage{{Name: "diff", Stage: 1}}, history)
+	assert.EqualError(t, err, `error building puzzle: unknown detail "diff", expected one of "body", "stat", or "patch"`)
+	_, err = build([]DetailStage{{Name: DetailBody, Stage: 1}, {Name: DetailBody, Stage: 2}}, history)
+	assert.EqualError(t, err, `error building puzzle: detail "body" is listed more than once`)
+	_, err = build([]DetailStage{{Name: DetailBody, Stage: 5}}, history)
+	assert.EqualError(t, err, `error building puzzle: detail "body" has stage 5, but it must be between 1 and 4`)
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+)
+
+// Difficulty chooses the order that a puzzle's commits are revealed in.
+type Difficulty string
+
+const (
+	// DifficultyNormal reveals the commits in a random order.
+	DifficultyNormal Difficulty = "normal"
+	// DifficultyEasy reveals the most distinctive commits first.
+	DifficultyEasy Difficulty = "easy"
+	// DifficultyHard reveals the most generic commits first and holds the distinctive ones back.
+	DifficultyHard Difficulty = "hard"
+)
+
+// ParseDifficulty parses the name of a difficulty. Empty means DifficultyNormal.
+func ParseDifficulty(s string) (Difficulty, error) {
+	switch difficulty := Difficulty(s); difficulty {
+	case "":
+		return DifficultyNormal, nil
+	case DifficultyNormal, DifficultyEasy, DifficultyHard:
+		return difficulty, nil
+	default:
+		return "", fmt.Errorf("unknown difficulty %q, expected one of %q, %q, or %q", s, DifficultyEasy, DifficultyNormal, DifficultyHard)
+	}
+}
+
+// orderByDifficulty sorts the puzzle's commits into the order they're revealed in for the difficulty. How
+// distinctive each commit is depends on the subjects of all the commits. Commits that are equally distinctive keep
+// their random order.
+func orderByDifficulty(puzzleCommits []git.Commit, difficulty Difficulty, allCommits []git.Commit) {
+	if difficulty == DifficultyNormal {
+		return
+	}
+
+	scorer := newSubjectScorer(allCommits)
+	scores := map[string]float64{}
+	for _, commit := range puzzleCommits {
+		scores[commit.Hash] = scorer.score(commit.SubjectLine)
+	}
+	slices.SortStableFunc(puzzleCommits, func(a, b git.Commit) int {
+		if difficulty == DifficultyEasy {
+			return cmp.Compare(scores[b.Hash], scores[a.Hash])
+		}
+		return cmp.Compare(scores[a.Hash], scores[b.Hash])
+	})
+}
+
+// subjectScorer scores how distinctive commit subjects are compared to the rest of the team's subjects using TF-IDF.
+type subjectScorer struct {
+	numSubjects int
+	// subjectsWithTerm is the number of subjects that each term is in.
+	subjectsWithTerm map[string]int
+}
+
+func newSubjectScorer(commits []git.Commit) subjectScorer {
+	scorer := subjectScorer{
+		numSubjects:      len(commits),
+		subjectsWithTerm: map[string]int{},
+	}
+	for _, commit := range commits {
+		seen := map[string]struct{}{}
+		for _, term := range subjectTerms(commit.SubjectLine) {
+			if _, ok := seen[term]; ok {
+				continue
+			}
+			seen[term] = struct{}{}
+			scorer.subjectsWithTerm[term]++
+		}
+	}
+
+	return scorer
+}
+
+// score returns the TF-IDF of the subject, which is the average of how rare each of its terms are. Subjects made of
+// words that everyone uses, like "fix tests", score low and subjects with unusual words score high.
+func (s subjectScorer) score(subject string) float64 {
+	terms := subjectTerms(subject)
+	if len(terms) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, term := range terms {
+		// Every term is in at least one subject, but the subject may not be one the scorer was built from.
+		total += math.Log(float64(s.numSubjects+1) / float64(s.subjectsWithTerm[term]+1))
+	}
+
+	return total / float64(len(terms))
+}
+
+// subjectTerms splits a subject into lowercase words.
+func subjectTerms(subject string) []string {
+	return strings.FieldsFunc(strings.ToLower(subject), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
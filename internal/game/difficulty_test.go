@@ -0,0 +1,78 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubjectScorer(t *testing.T) {
+	var commits []git.Commit
+	for i := range 20 {
+		commits = append(commits, git.Commit{SubjectLine: fmt.Sprintf("Fix tests (#%d)", i)})
+	}
+	commits = append(commits,
+		git.Commit{SubjectLine: "Fix flaky tests in the billing service"},
+		git.Commit{SubjectLine: "Add PKCE support to the OAuth login flow"},
+	)
+	scorer := newSubjectScorer(commits)
+
+	generic := scorer.score("Fix tests")
+	somewhatDistinctive := scorer.score("Fix flaky tests in the billing service")
+	distinctive := scorer.score("Add PKCE support to the OAuth login flow")
+	assert.Less(t, generic, somewhatDistinctive)
+	assert.Less(t, somewhatDistinctive, distinctive)
+	assert.Zero(t, scorer.score("!!!"))
+}
+
+func TestBuildPuzzle_Difficulty(t *testing.T) {
+	subjects := []string{
+		"Fix tests",
+		"Update dependencies",
+		"Fix lint",
+		"Migrate the invoice exporter to streaming CSV writes",
+	}
+	var commits []git.Commit
+	for i, subject := range subjects {
+		commits = append(commits, git.Commit{Hash: fmt.Sprint("alice", i), AuthorName: "Alice", AuthorEmail: "alice@example.com", SubjectLine: subject})
+	}
+	// Everyone else's commits make the generic subjects common, but they don't have enough commits to be the answer.
+	for _, name := range []string{"bob", "carol", "dave", "erin"} {
+		for i := range 3 {
+			commits = append(commits, git.Commit{Hash: fmt.Sprint(name, i), AuthorName: name, AuthorEmail: name + "@example.com", SubjectLine: subjects[i]})
+		}
+	}
+
+	build := func(difficulty Difficulty) []string {
+		puzzle, err := BuildPuzzle(
+			WithCommits(commits),
+			WithRandomSource(rand.NewSource(1)),
+			WithAuthorBias(1),
+			WithDifficulty(difficulty),
+		)
+		require.NoError(t, err)
+		require.Equal(t, "alice@example.com", puzzle.authorEmail)
+
+		var result []string
+		for _, clue := range puzzle.Clues(puzzle.NumStages() - 1) {
+			result = append(result, clue.Subject)
+		}
+		return result
+	}
+
+	easy := build(DifficultyEasy)
+	assert.Equal(t, "Migrate the invoice exporter to streaming CSV writes", easy[0])
+	hard := build(DifficultyHard)
+	assert.Equal(t, "Migrate the invoice exporter to streaming CSV writes", hard[len(hard)-1])
+	// Every difficulty uses the same commits.
+	assert.ElementsMatch(t, subjects, build(DifficultyNormal))
+	assert.ElementsMatch(t, subjects, easy)
+	assert.ElementsMatch(t, subjects, hard)
+
+	_, err := BuildPuzzle(WithCommits(commits), WithAuthorBias(1), WithDifficulty("nightmare"))
+	assert.EqualError(t, err, `unknown difficulty "nightmare", expected one of "easy", "normal", or "hard"`)
+}
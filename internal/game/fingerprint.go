@@ -0,0 +1,19 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprint returns a short hash identifying the puzzle's answer and commits.
+// Two players with the same fingerprint have the same puzzle, but the fingerprint doesn't reveal the answer.
+func (p Puzzle) Fingerprint() string {
+	hash := sha256.New()
+	hash.Write([]byte(p.authorEmail))
+	for _, commit := range p.puzzleCommits {
+		hash.Write([]byte{0})
+		hash.Write([]byte(commit.Hash))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
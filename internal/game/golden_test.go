@@ -0,0 +1,202 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/commit"
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goldenHistory builds a history of a commit a day over two years by authors with very different commit rates.
+// It must never change, since the golden puzzles depend on it.
+func goldenHistory() gittest.History {
+	authors := []git.Identity{
+		{Name: "Alice", Email: "alice@example.com"},
+		{Name: "Bob", Email: "bob@example.com"},
+		{Name: "Carol", Email: "carol@example.com"},
+		{Name: "Dave", Email: "dave@example.com"},
+		{Name: "Erin", Email: "erin@example.com"},
+		{Name: "Frank", Email: "frank@example.com"},
+	}
+	// Earlier authors commit much more often than later ones.
+	var schedule []git.Identity
+	for i, author := range authors {
+		for range len(authors) - i {
+			schedule = append(schedule, author)
+		}
+	}
+
+	base := time.Date(2023, time.January, 1, 15, 0, 0, 0, time.UTC)
+	var result []git.Commit
+	for i := range 730 {
+		author := schedule[(i*7)%len(schedule)]
+		date := base.AddDate(0, 0, i)
+		result = append([]git.Commit{{
+			Hash:        fmt.Sprintf("%040x", i),
+			AuthorName:  author.Name,
+			AuthorEmail: author.Email,
+			AuthorTime:  date,
+			CommitTime:  date,
+			SubjectLine: fmt.Sprintf("Update the widget module for change %d", i),
+			Files:       []git.FileChange{{Path: fmt.Sprintf("widget/file%d.go", i%4), Additions: 1}},
+		}}, result...)
+	}
+
+	return gittest.History{Commits: result}
+}
+
+func TestBuildPuzzle_Golden(t *testing.T) {
+	history := goldenHistory()
+	schedule := DefaultSchedule()
+
+	tests := []struct {
+		date      string
+		expAuthor string
+		// expCommits are the change numbers of the puzzle's commits.
+		expCommits     []int
+		expFingerprint string
+	}{{
+		date:           "2024-06-01",
+		expAuthor:      "bob@example.com",
+		expCommits:     []int{367, 397, 1, 55},
+		expFingerprint: "a308b1133fd4",
+	}, {
+		date:           "2024-06-02",
+		expAuthor:      "alice@example.com",
+		expCommits:     []int{141, 312, 9, 396},
+		expFingerprint: "0d510112ff4f",
+	}, {
+		date:           "2024-09-15",
+		expAuthor:      "carol@example.com",
+		expCommits:     []int{503, 278, 332, 539},
+		expFingerprint: "ae18c299766d",
+	}, {
+		date:           "2024-12-25",
+		expAuthor:      "carol@example.com",
+		expCommits:     []int{185, 485, 524, 602},
+		expFingerprint: "904ff853786c",
+	}, {
+		date:           "2025-01-07",
+		expAuthor:      "carol@example.com",
+		expCommits:     []int{401, 512, 509, 317},
+		expFingerprint: "7890c9971880",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.date, func(t *testing.T) {
+			day, err := schedule.ParseDay(tc.date)
+			require.NoError(t, err)
+			puzzle := buildGoldenPuzzle(t, history, day)
+
+			var puzzleCommits []int
+			for _, c := range puzzle.puzzleCommits {
+				var change int
+				_, err := fmt.Sscanf(c.SubjectLine, "Update the widget module for change %d", &change)
+				require.NoError(t, err)
+				puzzleCommits = append(puzzleCommits, change)
+			}
+			assert.Equal(t, tc.expAuthor, puzzle.authorEmail)
+			assert.Equal(t, tc.expCommits, puzzleCommits)
+			assert.Equal(t, tc.expFingerprint, puzzle.Fingerprint())
+		})
+	}
+}
+
+func TestBuildPuzzle_GoldenMultiRepository(t *testing.T) {
+	// Split the golden history between two repositories.
+	var api, web gittest.History
+	for i, c := range goldenHistory().Commits {
+		if i%4 < 2 {
+			api.Commits = append(api.Commits, c)
+		} else {
+			web.Commits = append(web.Commits, c)
+		}
+	}
+	history := git.NewMultiHistory(map[string]git.History{"api": api, "web": web})
+
+	day, err := DefaultSchedule().ParseDay("2024-06-01")
+	require.NoError(t, err)
+	puzzle := buildGoldenPuzzle(t, history, day)
+
+	var repositories []string
+	for _, c := range puzzle.puzzleCommits {
+		repositories = append(repositories, c.Repository)
+	}
+	assert.Equal(t, "bob@example.com", puzzle.authorEmail)
+	assert.Equal(t, []string{"web", "api", "api", "web"}, repositories)
+	assert.Contains(t, puzzle.Hints(puzzle.NumStages()-1), Hint{Description: "Author's most touched file", Value: "api/widget/file0.go"})
+	assert.True(t, puzzle.hints.showRepositories)
+	// Splitting the history between repositories shouldn't change the puzzle.
+	assert.Equal(t, "a308b1133fd4", puzzle.Fingerprint())
+}
+
+func TestBuildPuzzle_NumCommits(t *testing.T) {
+	day, err := DefaultSchedule().ParseDay("2024-06-01")
+	require.NoError(t, err)
+
+	tests := []struct {
+		numCommits int
+		// expHintsShown is how many hints are shown at each stage with the default hints.
+		expHintsShown []int
+	}{
+		{numCommits: 1, expHintsShown: []int{2}},
+		{numCommits: 2, expHintsShown: []int{0, 2}},
+		{numCommits: 6, expHintsShown: []int{0, 1, 1, 1, 1, 2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprint(tc.numCommits), func(t *testing.T) {
+			puzzle := buildGoldenPuzzle(t, goldenHistory(), day, WithNumCommits(tc.numCommits))
+			assert.Equal(t, tc.numCommits, puzzle.NumStages())
+			assert.Len(t, puzzle.Clues(tc.numCommits-1), tc.numCommits)
+
+			var hintsShown []int
+			for stage := range tc.numCommits {
+				hintsShown = append(hintsShown, puzzle.NumHintsShown(stage))
+			}
+			assert.Equal(t, tc.expHintsShown, hintsShown)
+		})
+	}
+
+	// Hints can't be revealed after the last stage.
+	_, err = BuildPuzzle(
+		WithCommits(goldenHistory().Commits),
+		WithAuthorBias(3.5),
+		WithNumCommits(2),
+		WithHints([]HintStage{{Name: "total_commits", Stage: 3}}),
+	)
+	assert.EqualError(t, err, `error building puzzle: hint "total_commits" has stage 3, but it must be between 1 and 2`)
+}
+
+// buildGoldenPuzzle builds the daily puzzle for the day with the default settings, unless they're overridden by opts.
+func buildGoldenPuzzle(t *testing.T, history git.History, day time.Time, opts ...Option) Puzzle {
+	t.Helper()
+
+	schedule := DefaultSchedule()
+	start, end, err := schedule.TimeRange(day, DefaultWindow(), history)
+	require.NoError(t, err)
+
+	filter, err := commit.BuildFilter(
+		commit.WithHistory(history),
+		commit.WithStartTime(start),
+		commit.WithEndTime(end),
+	)
+	require.NoError(t, err)
+	commits, err := filter.GetCommits()
+	require.NoError(t, err)
+
+	puzzle, err := BuildPuzzle(append([]Option{
+		WithCommits(commits),
+		WithRandomSource(rand.NewSource(int64(PuzzleNumber(day)))),
+		WithAuthorBias(3.5),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	return puzzle
+}
@@ -0,0 +1,336 @@
+package game
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+)
+
+// HintStage chooses when a hint is revealed.
+type HintStage struct {
+	// Name is the name of the hint in the catalog. See HintNames.
+	Name string
+	// Stage is the 1-indexed stage that the hint is revealed at.
+	Stage int
+}
+
+// DefaultHints returns the hints revealed when none are chosen for a puzzle with the given number of stages.
+func DefaultHints(numStages int) []HintStage {
+	return []HintStage{
+		{Name: "total_commits", Stage: min(2, numStages)},
+		{Name: "repository", Stage: numStages/2 + 1},
+		{Name: "most_touched_file", Stage: numStages},
+	}
+}
+
+// ParseHints parses a comma separated list of hints and the 1-indexed stages they're revealed at, e.g.
+// "total_commits:2,most_touched_file:4".
+func ParseHints(s string) ([]HintStage, error) {
+	return parseStages(s, "hint", func(name string, stage int) HintStage {
+		return HintStage{Name: name, Stage: stage}
+	})
+}
+
+// parseStages parses a comma separated list of names and the 1-indexed stages they're revealed at. The kind of thing
+// being revealed is used in errors.
+func parseStages[T any](s, kind string, newStage func(name string, stage int) T) ([]T, error) {
+	var result []T
+	for _, field := range strings.Split(s, ",") {
+		name, stage, ok := strings.Cut(strings.TrimSpace(field), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q, expected <name>:<stage>", kind, field)
+		}
+
+		stageNumber, err := strconv.Atoi(stage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage for %s %q: %w", kind, name, err)
+		}
+		result = append(result, newStage(name, stageNumber))
+	}
+
+	return result, nil
+}
+
+// hintKind is a hint that can be computed from an author's commits.
+type hintKind struct {
+	description string
+	value       func(commits []git.Commit) string
+	// revealsRepositories is set for the hint that shows which repository each commit is from instead of a value. It's
+	// only shown when the commits come from more than one repository.
+	revealsRepositories bool
+}
+
+// hintCatalog are all the hints that can be revealed, by name.
+var hintCatalog = map[string]hintKind{
+	"total_commits": {
+		description: "Number of commits made by author in the game's window",
+		value: func(commits []git.Commit) string {
+			return strconv.Itoa(len(commits))
+		},
+	},
+	"repository": {
+		revealsRepositories: true,
+	},
+	"most_touched_file": {
+		description: "Author's most touched file",
+		value:       mostTouchedFile,
+	},
+	"commit_hour": {
+		description: "Hour of the day the author usually commits at",
+		value:       commitHour,
+	},
+	"busiest_weekday": {
+		description: "Day of the week the author commits the most on",
+		value:       busiestWeekday,
+	},
+	"top_directory": {
+		description: "Author's most touched top-level directory",
+		value:       topDirectory,
+	},
+	"top_extension": {
+		description: "Author's most common file extension",
+		value:       topExtension,
+	},
+	"median_lines_changed": {
+		description: "Median number of lines changed in the author's commits",
+		value:       medianLinesChanged,
+	},
+	"first_commit_date": {
+		description: "Date of the author's earliest commit in the game",
+		value:       firstCommitDate,
+	},
+	"test_percentage": {
+		description: "Percentage of the author's commits that change tests",
+		value:       testPercentage,
+	},
+}
+
+// HintNames returns the names of every hint in the catalog, sorted.
+func HintNames() []string {
+	var names []string
+	for name := range hintCatalog {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// scheduledHint is a hint along with the 0-indexed stage it's revealed at.
+type scheduledHint struct {
+	Hint
+	stage int
+}
+
+// scheduleHints computes the hints about the author's commits, sorted by the stage they're revealed at. The repositories
+// are only shown if multiRepository is set.
+func scheduleHints(stages []HintStage, numStages int, commits []git.Commit, multiRepository bool) (puzzleHints, error) {
+	seen := map[string]struct{}{}
+	var result puzzleHints
+	for _, hintStage := range stages {
+		kind, ok := hintCatalog[hintStage.Name]
+		if !ok {
+			return puzzleHints{}, fmt.Errorf("unknown hint %q, expected one of %s", hintStage.Name, strings.Join(HintNames(), ", "))
+		}
+		if _, ok := seen[hintStage.Name]; ok {
+			return puzzleHints{}, fmt.Errorf("hint %q is listed more than once", hintStage.Name)
+		}
+		seen[hintStage.Name] = struct{}{}
+		if hintStage.Stage < 1 || hintStage.Stage > numStages {
+			return puzzleHints{}, fmt.Errorf("hint %q has stage %d, but it must be between 1 and %d", hintStage.Name, hintStage.Stage, numStages)
+		}
+
+		if kind.revealsRepositories {
+			result.showRepositories = multiRepository
+			result.repositoryStage = hintStage.Stage - 1
+			continue
+		}
+		result.hints = append(result.hints, scheduledHint{
+			Hint:  Hint{Description: kind.description, Value: kind.value(commits)},
+			stage: hintStage.Stage - 1,
+		})
+	}
+
+	slices.SortStableFunc(result.hints, func(a, b scheduledHint) int {
+		return cmp.Compare(a.stage, b.stage)
+	})
+
+	return result, nil
+}
+
+// noFiles is the value of the hints about files when none of the author's commits changed any, e.g. because they're
+// all empty or merge commits.
+const noFiles = "(no files changed)"
+
+// mostCommon returns the key with the highest count.
+// Ties are broken by picking the smallest key so that the result is stable.
+func mostCommon[K cmp.Ordered](counts map[K]int) K {
+	var result K
+	maxCount := 0
+	for key, count := range counts {
+		if count > maxCount || (count == maxCount && key < result) {
+			result = key
+			maxCount = count
+		}
+	}
+
+	return result
+}
+
+// mostTouchedFile returns the file changed by the most commits.
+func mostTouchedFile(commits []git.Commit) string {
+	counts := map[string]int{}
+	for _, commit := range commits {
+		for _, file := range commit.Files {
+			counts[repositoryPath(commit, file)]++
+		}
+	}
+	if len(counts) == 0 {
+		return noFiles
+	}
+
+	return mostCommon(counts)
+}
+
+// commitHour returns the hour of the day that the most commits were authored in, in the author's time zone.
+func commitHour(commits []git.Commit) string {
+	counts := map[int]int{}
+	for _, commit := range commits {
+		counts[commit.AuthorTime.Hour()]++
+	}
+
+	return fmt.Sprintf("%02d:00", mostCommon(counts))
+}
+
+// busiestWeekday returns the day of the week that the most commits were authored on, in the author's time zone.
+func busiestWeekday(commits []git.Commit) string {
+	counts := map[time.Weekday]int{}
+	for _, commit := range commits {
+		counts[commit.AuthorTime.Weekday()]++
+	}
+
+	return mostCommon(counts).String()
+}
+
+// repositoryPath returns the path of a file changed by the commit.
+// Paths are prefixed by their repository when the commits are from multiple repositories.
+func repositoryPath(commit git.Commit, file git.FileChange) string {
+	if commit.Repository != "" {
+		return commit.Repository + "/" + file.Path
+	}
+
+	return file.Path
+}
+
+// topDirectory returns the top-level directory changed by the most commits.
+func topDirectory(commits []git.Commit) string {
+	counts := map[string]int{}
+	for _, commit := range commits {
+		directories := map[string]struct{}{}
+		for _, file := range commit.Files {
+			directory, _, ok := strings.Cut(file.Path, "/")
+			if !ok {
+				directory = "."
+			}
+			if commit.Repository != "" {
+				directory = path.Join(commit.Repository, directory)
+			}
+			directories[directory] = struct{}{}
+		}
+
+		// Count each directory once per commit so that commits changing many files don't dominate.
+		for directory := range directories {
+			counts[directory]++
+		}
+	}
+	if len(counts) == 0 {
+		return noFiles
+	}
+
+	return mostCommon(counts) + "/"
+}
+
+// topExtension returns the file extension of the most changed files.
+func topExtension(commits []git.Commit) string {
+	counts := map[string]int{}
+	for _, commit := range commits {
+		for _, file := range commit.Files {
+			extension := path.Ext(file.Path)
+			if extension == "" {
+				extension = "(none)"
+			}
+			counts[extension]++
+		}
+	}
+	if len(counts) == 0 {
+		return noFiles
+	}
+
+	return mostCommon(counts)
+}
+
+// medianLinesChanged returns the median of the number of lines added and deleted by each commit.
+func medianLinesChanged(commits []git.Commit) string {
+	var linesChanged []int
+	for _, commit := range commits {
+		lines := 0
+		for _, file := range commit.Files {
+			lines += file.Additions + file.Deletions
+		}
+		linesChanged = append(linesChanged, lines)
+	}
+	slices.Sort(linesChanged)
+
+	middle := len(linesChanged) / 2
+	if len(linesChanged)%2 == 0 {
+		return strconv.Itoa((linesChanged[middle-1] + linesChanged[middle]) / 2)
+	}
+
+	return strconv.Itoa(linesChanged[middle])
+}
+
+// firstCommitDate returns the date that the earliest commit was authored on.
+func firstCommitDate(commits []git.Commit) string {
+	first := slices.MinFunc(commits, func(a, b git.Commit) int {
+		return a.AuthorTime.Compare(b.AuthorTime)
+	})
+
+	return first.AuthorTime.Format(time.DateOnly)
+}
+
+// testPercentage returns the percentage of commits that change a test file.
+func testPercentage(commits []git.Commit) string {
+	numTestCommits := 0
+	for _, commit := range commits {
+		if slices.ContainsFunc(commit.Files, func(file git.FileChange) bool { return isTestFile(file.Path) }) {
+			numTestCommits++
+		}
+	}
+
+	return fmt.Sprintf("%d%%", numTestCommits*100/len(commits))
+}
+
+// testDirectories are the names of directories that conventionally hold tests.
+var testDirectories = []string{"test", "tests", "__tests__", "spec", "specs"}
+
+// isTestFile returns whether the path looks like a test following the conventions of common languages.
+func isTestFile(filePath string) bool {
+	directories := strings.Split(path.Dir(filePath), "/")
+	if slices.ContainsFunc(directories, func(directory string) bool { return slices.Contains(testDirectories, directory) }) {
+		return true
+	}
+
+	name := strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+	return strings.HasSuffix(name, "_test") ||
+		strings.HasSuffix(name, ".test") ||
+		strings.HasSuffix(name, ".spec") ||
+		strings.HasPrefix(name, "test_") ||
+		strings.HasSuffix(name, "Test") ||
+		strings.HasSuffix(name, "Tests")
+}
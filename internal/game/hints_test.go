@@ -0,0 +1,139 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHintCatalog(t *testing.T) {
+	eastern := time.FixedZone("EST", -5*60*60)
+	commits := []git.Commit{{
+		// A Tuesday, at 9pm in the author's time zone.
+		AuthorTime: time.Date(2024, time.May, 7, 21, 30, 0, 0, eastern),
+		Files: []git.FileChange{
+			{Path: "src/server/server.go", Additions: 10, Deletions: 2},
+			{Path: "src/server/server_test.go", Additions: 20},
+		},
+	}, {
+		AuthorTime: time.Date(2024, time.May, 14, 21, 5, 0, 0, eastern),
+		Files: []git.FileChange{
+			{Path: "src/server/server.go", Additions: 1, Deletions: 1},
+			{Path: "README", Additions: 3},
+		},
+	}, {
+		AuthorTime: time.Date(2024, time.April, 2, 9, 0, 0, 0, eastern),
+		Files: []git.FileChange{
+			{Path: "docs/guide.md", Additions: 40, Deletions: 10},
+		},
+	}, {
+		// A Wednesday.
+		AuthorTime: time.Date(2024, time.May, 15, 9, 0, 0, 0, time.UTC),
+		Files: []git.FileChange{
+			{Path: "src/client/client.go", Additions: 2},
+			{Path: "tests/integration/client.py", Additions: 6},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		expValue string
+	}{
+		{name: "total_commits", expValue: "4"},
+		{name: "most_touched_file", expValue: "src/server/server.go"},
+		// 9pm and 9am are tied, so the earlier hour is picked.
+		{name: "commit_hour", expValue: "09:00"},
+		{name: "busiest_weekday", expValue: "Tuesday"},
+		{name: "top_directory", expValue: "src/"},
+		{name: "top_extension", expValue: ".go"},
+		// The commits changed 32, 5, 50, and 8 lines.
+		{name: "median_lines_changed", expValue: "20"},
+		{name: "first_commit_date", expValue: "2024-04-02"},
+		{name: "test_percentage", expValue: "50%"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Contains(t, hintCatalog, tc.name)
+			assert.Equal(t, tc.expValue, hintCatalog[tc.name].value(commits))
+		})
+	}
+	// The repository hint doesn't have a value. TestScheduleHints covers it.
+	assert.Len(t, tests, len(hintCatalog)-1, "every hint should be tested")
+}
+
+func TestHintCatalog_NoFiles(t *testing.T) {
+	commits := []git.Commit{{Repository: "api"}, {}}
+
+	for _, name := range []string{"most_touched_file", "top_directory", "top_extension"} {
+		assert.Equal(t, "(no files changed)", hintCatalog[name].value(commits), name)
+	}
+}
+
+func TestHintCatalog_MultiRepository(t *testing.T) {
+	commits := []git.Commit{
+		{Repository: "api", Files: []git.FileChange{{Path: "main.go"}, {Path: "cmd/serve.go"}}},
+		{Repository: "api", Files: []git.FileChange{{Path: "main.go"}}},
+		{Repository: "web", Files: []git.FileChange{{Path: "cmd/serve.go"}}},
+	}
+
+	assert.Equal(t, "api/main.go", mostTouchedFile(commits))
+	assert.Equal(t, "api/", topDirectory(commits))
+}
+
+func TestIsTestFile(t *testing.T) {
+	for _, path := range []string{"server_test.go", "src/app.test.ts", "src/app.spec.js", "test_app.py", "src/AppTest.java", "tests/fixture.json", "src/__tests__/app.js"} {
+		assert.True(t, isTestFile(path), path)
+	}
+	for _, path := range []string{"testing.go", "src/contest.go", "attest/main.go", "latest.txt"} {
+		assert.False(t, isTestFile(path), path)
+	}
+}
+
+func TestScheduleHints(t *testing.T) {
+	commits := []git.Commit{{Files: []git.FileChange{{Path: "main.go", Additions: 1}}}}
+
+	hints, err := scheduleHints([]HintStage{
+		{Name: "top_extension", Stage: 3},
+		{Name: "total_commits", Stage: 1},
+		{Name: "repository", Stage: 2},
+		{Name: "test_percentage", Stage: 3},
+	}, 4, commits, true)
+	require.NoError(t, err)
+	assert.True(t, hints.showRepositories)
+	assert.Equal(t, 1, hints.repositoryStage)
+	assert.Equal(t, []scheduledHint{
+		{Hint: Hint{Description: "Number of commits made by author in the game's window", Value: "1"}, stage: 0},
+		{Hint: Hint{Description: "Author's most common file extension", Value: ".go"}, stage: 2},
+		{Hint: Hint{Description: "Percentage of the author's commits that change tests", Value: "0%"}, stage: 2},
+	}, hints.hints)
+
+	// The repositories aren't shown unless the hint is scheduled and the commits come from more than one repository.
+	hints, err = scheduleHints([]HintStage{{Name: "repository", Stage: 1}}, 4, commits, false)
+	require.NoError(t, err)
+	assert.False(t, hints.showRepositories)
+	hints, err = scheduleHints([]HintStage{{Name: "total_commits", Stage: 1}}, 4, commits, true)
+	require.NoError(t, err)
+	assert.False(t, hints.showRepositories)
+
+	_, err = scheduleHints([]HintStage{{Name: "shoe_size", Stage: 1}}, 4, commits, false)
+	assert.ErrorContains(t, err, `unknown hint "shoe_size", expected one of busiest_weekday, commit_hour`)
+	_, err = scheduleHints([]HintStage{{Name: "total_commits", Stage: 1}, {Name: "total_commits", Stage: 2}}, 4, commits, false)
+	assert.EqualError(t, err, `hint "total_commits" is listed more than once`)
+	_, err = scheduleHints([]HintStage{{Name: "total_commits", Stage: 5}}, 4, commits, false)
+	assert.EqualError(t, err, `hint "total_commits" has stage 5, but it must be between 1 and 4`)
+}
+
+func TestParseHints(t *testing.T) {
+	hints, err := ParseHints("total_commits:2, commit_hour:1")
+	require.NoError(t, err)
+	assert.Equal(t, []HintStage{{Name: "total_commits", Stage: 2}, {Name: "commit_hour", Stage: 1}}, hints)
+
+	_, err = ParseHints("total_commits")
+	assert.EqualError(t, err, `invalid hint "total_commits", expected <name>:<stage>`)
+	_, err = ParseHints("total_commits:last")
+	assert.ErrorContains(t, err, `invalid stage for hint "total_commits"`)
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// The events written by RunJSON.
+type (
+	startEvent struct {
+		Event     string   `json:"event"`
+		NumStages int      `json:"num_stages"`
+		Authors   []Author `json:"authors"`
+	}
+
+	stageEvent struct {
+		Event string `json:"event"`
+		// Stage is 1-indexed.
+		Stage   int    `json:"stage"`
+		Commits []Clue `json:"commits"`
+		Hints   []Hint `json:"hints"`
+	}
+
+	guessEvent struct {
+		Event   string `json:"event"`
+		Guess   string `json:"guess"`
+		Correct bool   `json:"correct"`
+	}
+
+	resultEvent struct {
+		Event       string   `json:"event"`
+		Won         bool     `json:"won"`
+		SolvedStage int      `json:"solved_stage"`
+		NumStages   int      `json:"num_stages"`
+		Guesses     []string `json:"guesses"`
+		Answer      Author   `json:"answer"`
+	}
+
+	errorEvent struct {
+		Event string `json:"event"`
+		Error string `json:"error"`
+	}
+)
+
+type guessInput struct {
+	Guess string `json:"guess"`
+}
+
+// RunJSON plays the puzzle by reading guesses from in and writing events to out, so that it can be played by other
+// programs. Both are newline-delimited JSON.
+//
+// Each line of input is a guess like {"guess": "<author e-mail>"}. The events written are:
+//   - {"event": "start", ...} with the number of stages and the authors that can be guessed.
+//   - {"event": "stage", ...} with the commits and hints revealed at the start of each stage.
+//   - {"event": "guess", ...} after each guess, with whether it was correct.
+//   - {"event": "error", ...} after input that isn't a valid guess. The guess doesn't count, so another can be made.
+//   - {"event": "result", ...} once the game is over, with the answer.
+func (p Puzzle) RunJSON(in io.Reader, out io.Writer) (Result, error) {
+	s := NewSession(p)
+	encoder := json.NewEncoder(out)
+	// Commit subjects and e-mails are easier for people to read without escaped characters.
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(startEvent{Event: "start", NumStages: p.NumStages(), Authors: p.Authors()})
+	if err != nil {
+		return Result{}, err
+	}
+
+	scanner := bufio.NewScanner(in)
+	for !s.Finished() {
+		err := encoder.Encode(stageEvent{
+			Event:   "stage",
+			Stage:   s.CurrentStage() + 1,
+			Commits: s.RevealedClues(),
+			Hints:   append([]Hint{}, s.RevealedHints()...),
+		})
+		if err != nil {
+			return Result{}, err
+		}
+
+		correct, guess, err := readGuess(scanner, s, encoder)
+		if err != nil {
+			return Result{}, err
+		}
+
+		err = encoder.Encode(guessEvent{Event: "guess", Guess: guess, Correct: correct})
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	result := s.Result()
+	err = encoder.Encode(resultEvent{
+		Event:       "result",
+		Won:         result.Won,
+		SolvedStage: result.SolvedStage(),
+		NumStages:   result.NumStages,
+		Guesses:     result.Guesses,
+		Answer:      p.Answer(),
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
+
+// readGuess reads lines of input until one is a valid guess and makes the guess.
+func readGuess(scanner *bufio.Scanner, s *Session, encoder *json.Encoder) (bool, string, error) {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var input guessInput
+		err := json.Unmarshal([]byte(line), &input)
+		if err != nil {
+			err = errors.New("invalid guess: " + err.Error())
+		} else {
+			var result Result
+			result, err = s.Guess(input.Guess)
+			if err == nil {
+				return result.Won, input.Guess, nil
+			}
+		}
+
+		err = encoder.Encode(errorEvent{Event: "error", Error: err.Error()})
+		if err != nil {
+			return false, "", err
+		}
+	}
+
+	if scanner.Err() != nil {
+		return false, "", scanner.Err()
+	}
+
+	return false, "", errors.New("the input ended before the game was over")
+}
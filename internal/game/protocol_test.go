@@ -0,0 +1,76 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPuzzle_RunJSON(t *testing.T) {
+	day, err := DefaultSchedule().ParseDay("2024-06-01")
+	require.NoError(t, err)
+	// The answer is bob@example.com.
+	puzzle := buildGoldenPuzzle(t, goldenHistory(), day)
+
+	input := strings.Join([]string{
+		`{"guess": "alice@example.com"}`,
+		``,
+		`not json`,
+		`{"guess": "nobody@example.com"}`,
+		`{"guess": "carol@example.com"}`,
+		`{"guess": "bob@example.com"}`,
+		`{"guess": "alice@example.com"}`,
+	}, "\n")
+	var out bytes.Buffer
+	result, err := puzzle.RunJSON(strings.NewReader(input), &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"alice@example.com", "carol@example.com", "bob@example.com"}, result.Guesses)
+	assert.True(t, result.Won)
+	assert.Equal(t, 3, result.SolvedStage())
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &event), line)
+		events = append(events, event)
+	}
+
+	var types []string
+	for _, event := range events {
+		types = append(types, event["event"].(string))
+	}
+	assert.Equal(t, []string{"start", "stage", "guess", "stage", "error", "error", "guess", "stage", "guess", "result"}, types)
+
+	assert.EqualValues(t, 4, events[0]["num_stages"])
+	assert.Len(t, events[0]["authors"], 3)
+
+	assert.EqualValues(t, 1, events[1]["stage"])
+	assert.Len(t, events[1]["commits"], 1)
+	assert.Empty(t, events[1]["hints"])
+	assert.Equal(t, map[string]any{"event": "guess", "guess": "alice@example.com", "correct": false}, events[2])
+
+	assert.EqualValues(t, 2, events[3]["stage"])
+	assert.Len(t, events[3]["commits"], 2)
+	assert.Len(t, events[3]["hints"], 1)
+	assert.Contains(t, events[4]["error"], "invalid guess")
+	assert.Equal(t, `unknown author "nobody@example.com"`, events[5]["error"])
+
+	assert.Equal(t, map[string]any{"event": "guess", "guess": "bob@example.com", "correct": true}, events[8])
+	assert.Equal(t, true, events[9]["won"])
+	assert.EqualValues(t, 3, events[9]["solved_stage"])
+	assert.Equal(t, map[string]any{"name": "Bob", "email": "bob@example.com"}, events[9]["answer"])
+}
+
+func TestPuzzle_RunJSON_InputEnded(t *testing.T) {
+	day, err := DefaultSchedule().ParseDay("2024-06-01")
+	require.NoError(t, err)
+	puzzle := buildGoldenPuzzle(t, goldenHistory(), day)
+
+	_, err = puzzle.RunJSON(strings.NewReader(`{"guess": "alice@example.com"}`), &bytes.Buffer{})
+	assert.EqualError(t, err, "the input ended before the game was over")
+}
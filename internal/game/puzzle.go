@@ -1,7 +1,10 @@
 package game
 
 import (
+	"cmp"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/JosephNaberhaus/prompt"
@@ -14,37 +17,167 @@ const youWin = "                              _       \n _   _  ___  _   _  __
 const youLose = "                     _                \n _   _  ___  _   _  | | ___  ___  ___ \n| | | |/ _ \\| | | | | |/ _ \\/ __|/ _ \\\n| |_| | (_) | |_| | | | (_) \\__ \\  __/\n \\__, |\\___/ \\__,_| |_|\\___/|___/\\___|\n |___/                                "
 const nope = "                        \n _ __   ___  _ __   ___ \n| '_ \\ / _ \\| '_ \\ / _ \\\n| | | | (_) | |_) |  __/\n|_| |_|\\___/| .__/ \\___|\n            |_|         "
 
-const numPuzzleCommits = 4
+// DefaultNumCommits is the number of commits shown in a puzzle, which is also the number of guesses, by default.
+const DefaultNumCommits = 4
+
+// NumHintsShown returns how many hints are shown at the given 0-indexed stage.
+func (p Puzzle) NumHintsShown(stage int) int {
+	shown := len(p.Hints(stage))
+	if p.hints.showRepositories && stage >= p.hints.repositoryStage {
+		shown++
+	}
+
+	return shown
+}
 
 type puzzleHints struct {
-	totalCommits    int
-	mostTouchedFile string
+	// hints are the hints about the author, sorted by the stage they're revealed at.
+	hints []scheduledHint
+	// showRepositories is whether to reveal which repository each commit is from. This is only done when the
+	// repository hint is scheduled and the puzzle's commits come from multiple repositories.
+	showRepositories bool
+	// repositoryStage is the 0-indexed stage that the repositories are revealed at.
+	repositoryStage int
 }
 
 type Puzzle struct {
 	authorEmail   string
 	authorName    string
 	authorCommits []git.Commit
-	puzzleCommits [numPuzzleCommits]git.Commit
+	puzzleCommits []git.Commit
 
-	hints puzzleHints
+	hints   puzzleHints
+	details puzzleDetails
 
 	// All commits by all users.
 	allCommits     []git.Commit
 	allAuthorNames map[string]string
+
+	// acceptCoAuthors specifies whether guessing a co-author of a revealed commit is correct.
+	acceptCoAuthors bool
+}
+
+// Result is the outcome of a played puzzle.
+type Result struct {
+	// Guesses are the e-mails of the authors guessed, in order.
+	Guesses []string
+	// HintsShown is how many hints were shown when each guess was made.
+	HintsShown []int
+	// Won is whether the last guess was correct.
+	Won bool
+	// NumStages is how many stages the puzzle had.
+	NumStages int
+}
+
+// SolvedStage is the 1-indexed stage the puzzle was solved at, or 0 if it wasn't solved.
+func (r Result) SolvedStage() int {
+	if !r.Won {
+		return 0
+	}
+
+	return len(r.Guesses)
+}
+
+// Author is a person that can be guessed.
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Clue is a commit revealed to the player.
+type Clue struct {
+	Subject string `json:"subject"`
+	// Repository is the repository the commit is from. It's empty until the repository hint is revealed.
+	Repository string `json:"repository,omitempty"`
+	// Body is the rest of the commit message after the subject. It's empty until it's revealed.
+	Body string `json:"body,omitempty"`
+	// Stat summarizes the files the commit changed, like "git diff --stat". It's empty until it's revealed.
+	Stat string `json:"stat,omitempty"`
+	// Patch is the first lines the commit changed. It's empty until it's revealed.
+	Patch string `json:"patch,omitempty"`
+}
+
+// Hint is a fact about the author revealed to the player.
+type Hint struct {
+	Description string `json:"description"`
+	Value       string `json:"value"`
+}
+
+// NumStages returns how many stages the puzzle has. A guess is made at each stage.
+func (p Puzzle) NumStages() int {
+	return len(p.puzzleCommits)
+}
+
+// Authors returns everyone that can be guessed, sorted by name.
+func (p Puzzle) Authors() []Author {
+	var result []Author
+	for email, name := range p.allAuthorNames {
+		result = append(result, Author{Name: name, Email: email})
+	}
+	// Sort so that the authors are shown in a stable order rather than map iteration order.
+	slices.SortFunc(result, func(a, b Author) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.Email, b.Email))
+	})
+
+	return result
+}
+
+// Answer returns the author of the puzzle's commits. This shouldn't be shown to the player until the game is over.
+func (p Puzzle) Answer() Author {
+	return Author{Name: p.authorName, Email: p.authorEmail}
+}
+
+// Clues returns the commits revealed at the given 0-indexed stage.
+func (p Puzzle) Clues(stage int) []Clue {
+	var result []Clue
+	for i, commit := range p.puzzleCommits[:min(stage, len(p.puzzleCommits)-1)+1] {
+		clue := Clue{Subject: commit.SubjectLine}
+		if p.hints.showRepositories && stage >= p.hints.repositoryStage {
+			clue.Repository = commit.Repository
+		}
+		if p.details.revealed(DetailBody, stage) {
+			clue.Body = p.details.commits[i].body
+		}
+		if p.details.revealed(DetailStat, stage) {
+			clue.Stat = p.details.commits[i].stat
+		}
+		if p.details.revealed(DetailPatch, stage) {
+			clue.Patch = p.details.commits[i].patch
+		}
+		result = append(result, clue)
+	}
+
+	return result
 }
 
-func (p Puzzle) Run() error {
+// Hints returns the hints revealed at the given 0-indexed stage.
+func (p Puzzle) Hints(stage int) []Hint {
+	var result []Hint
+	for _, hint := range p.hints.hints {
+		if stage >= hint.stage {
+			result = append(result, hint.Hint)
+		}
+	}
+
+	return result
+}
+
+// Run plays the puzzle in the terminal.
+func (p Puzzle) Run() (Result, error) {
+	s := NewSession(p)
+
 	var promptOptions []prompt.SelectionOption
-	for authorEmail, authorName := range p.allAuthorNames {
+	for _, author := range p.Authors() {
 		promptOptions = append(promptOptions, prompt.SelectionOption{
-			ID:          authorEmail,
-			Name:        authorName,
-			Description: authorEmail,
+			ID:          author.Email,
+			Name:        author.Name,
+			Description: author.Email,
 		})
 	}
 
-	for stage := 0; stage < numPuzzleCommits; stage++ {
+	for !s.Finished() {
+		stage := s.CurrentStage()
+
 		output.ClearScreen()
 		output.PrintColorLn(header, output.Yellow)
 		output.Ln()
@@ -56,24 +189,26 @@ func (p Puzzle) Run() error {
 		output.PrintColorLn(":", output.Yellow)
 		output.Ln()
 
-		for i := 0; i <= stage; i++ {
+		for i, clue := range s.RevealedClues() {
 			output.PrintColor("Commit #", output.Green)
 			output.PrintColor(strconv.Itoa(i+1), output.Green)
+			if clue.Repository != "" {
+				output.PrintColor(" ("+clue.Repository+")", output.Green)
+			}
 			output.PrintColor(": ", output.Green)
-			output.PrintColorLn(p.puzzleCommits[i].SubjectLine, output.White)
+			output.PrintColorLn(clue.Subject, output.White)
+			printClueDetails(clue)
 		}
 
 		// Hints
 		output.Ln()
-		if stage >= 1 {
+		if hints := s.RevealedHints(); len(hints) > 0 {
 			output.Ln()
 			output.PrintColorLn("Hints", output.Green)
-			output.PrintColor("Number of commits made by author in the last year: ", output.Green)
-			output.PrintColorLn(strconv.Itoa(p.hints.totalCommits), output.White)
-		}
-		if stage >= 3 {
-			output.PrintColor("Author's most touched file: ", output.Green)
-			output.PrintColorLn(p.hints.mostTouchedFile, output.White)
+			for _, hint := range hints {
+				output.PrintColor(hint.Description+": ", output.Green)
+				output.PrintColorLn(hint.Value, output.White)
+			}
 		}
 
 		output.Ln()
@@ -84,18 +219,21 @@ func (p Puzzle) Run() error {
 		}
 		err := answerPrompt.Show()
 		if err != nil {
-			return err
+			return Result{}, err
+		}
+
+		result, err := s.Guess(answerPrompt.Response().ID)
+		if err != nil {
+			return Result{}, err
 		}
 
-		if answerPrompt.Response().ID == p.authorEmail {
+		switch {
+		case result.Won:
 			flashMessage(youWin, output.Green)
-			break
-		} else {
-			if stage == numPuzzleCommits-1 {
-				flashMessage(youLose, output.Red)
-			} else {
-				flashMessage(nope, output.Red)
-			}
+		case s.Finished():
+			flashMessage(youLose, output.Red)
+		default:
+			flashMessage(nope, output.Red)
 		}
 	}
 
@@ -107,7 +245,59 @@ func (p Puzzle) Run() error {
 	output.PrintColorLn(")", output.White)
 	output.Ln()
 
-	return nil
+	return s.Result(), nil
+}
+
+// printClueDetails prints the revealed details of a commit indented under its subject.
+func printClueDetails(clue Clue) {
+	for _, line := range detailLines(clue.Body) {
+		output.PrintColorLn("    "+line, output.White)
+	}
+	for _, line := range detailLines(clue.Stat) {
+		output.PrintColorLn("    "+line, output.Yellow)
+	}
+	for _, line := range detailLines(clue.Patch) {
+		color := output.White
+		switch {
+		case strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ "):
+			// Both header lines of a file start with the same characters as removed and added lines, but they're
+			// always in a pair.
+		case strings.HasPrefix(line, "+"):
+			color = output.Green
+		case strings.HasPrefix(line, "-"):
+			color = output.Red
+		}
+		output.PrintColorLn("    "+line, color)
+	}
+}
+
+// detailLines splits the text of a detail into lines. There are none if the detail isn't revealed.
+func detailLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
+
+// IsCorrect returns whether guessing the author with the given e-mail is correct at the given 0-indexed stage.
+func (p Puzzle) IsCorrect(guessEmail string, stage int) bool {
+	if guessEmail == p.authorEmail {
+		return true
+	}
+	if !p.acceptCoAuthors {
+		return false
+	}
+
+	for _, commit := range p.puzzleCommits[:stage+1] {
+		for _, coAuthor := range commit.CoAuthors {
+			if coAuthor.Email == guessEmail {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 func flashMessage(message string, color output.Color) {
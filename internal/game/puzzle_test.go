@@ -0,0 +1,94 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/command"
+	"github.com/josephnaberhaus/gauthordle/internal/commit"
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPuzzle_FromRepository(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	base := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	authors := []struct {
+		identity   string
+		numCommits int
+	}{
+		{"Alice <alice@example.com>", 12},
+		{"Bob <bob@example.com>", 6},
+		{"Carol <carol@example.com>", 4},
+		// Too few commits to be picked.
+		{"Dave <dave@example.com>", 2},
+		// Bots are filtered out.
+		{"Dependabot <dependabot[bot]@users.noreply.github.com>", 10},
+	}
+	day := 0
+	for _, author := range authors {
+		for i := range author.numCommits {
+			repo.Commit(gittest.Commit{
+				Author:  author.identity,
+				Date:    base.Add(time.Duration(day) * 24 * time.Hour),
+				Message: fmt.Sprintf("change number %d by %s", i, author.identity),
+				Files:   map[string]string{fmt.Sprintf("src/file%d.go", i%3): fmt.Sprintf("version %d\n", day)},
+			})
+			day++
+		}
+	}
+	// Too short to be a useful clue.
+	repo.Commit(gittest.Commit{Author: authors[0].identity, Date: base, Message: "fix"})
+
+	schedule := DefaultSchedule()
+	puzzleDay, err := schedule.ParseDay("2024-06-01")
+	require.NoError(t, err)
+
+	build := func(t *testing.T, history git.History) Puzzle {
+		start, end, err := schedule.TimeRange(puzzleDay, DefaultWindow(), history)
+		require.NoError(t, err)
+
+		filter, err := commit.BuildFilter(
+			commit.WithHistory(history),
+			commit.WithStartTime(start),
+			commit.WithEndTime(end),
+		)
+		require.NoError(t, err)
+		commits, err := filter.GetCommits()
+		require.NoError(t, err)
+		assert.Len(t, commits, 24)
+
+		puzzle, err := BuildPuzzle(
+			WithCommits(commits),
+			WithRandomSource(rand.NewSource(int64(PuzzleNumber(puzzleDay)))),
+			WithAuthorBias(3.5),
+		)
+		require.NoError(t, err)
+
+		return puzzle
+	}
+
+	found, err := git.FindRepository(repo.Dir)
+	require.NoError(t, err)
+	puzzle := build(t, git.NewNativeHistory(found))
+
+	assert.Contains(t, []string{"alice@example.com", "bob@example.com", "carol@example.com"}, puzzle.authorEmail)
+	assert.Equal(t, strconv.Itoa(len(puzzle.authorCommits)), puzzle.Hints(1)[0].Value)
+	assert.Len(t, puzzle.allAuthorNames, 4)
+	seen := map[string]struct{}{}
+	for _, c := range puzzle.puzzleCommits {
+		assert.Equal(t, puzzle.authorEmail, c.AuthorEmail)
+		seen[c.Hash] = struct{}{}
+	}
+	assert.Len(t, seen, DefaultNumCommits)
+
+	// The same day always builds the same puzzle, whichever way the history is read.
+	assert.Equal(t, puzzle, build(t, git.NewNativeHistory(found)))
+	assert.Equal(t, puzzle, build(t, git.CLIHistory{Runner: command.ExecRunner{Dir: repo.Dir}}))
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Session is a puzzle being played. It has the rules of the game, so that front-ends only need to present it.
+// A Session isn't safe for concurrent use.
+type Session struct {
+	puzzle   Puzzle
+	stage    int
+	result   Result
+	finished bool
+}
+
+func NewSession(puzzle Puzzle) *Session {
+	return &Session{
+		puzzle: puzzle,
+		result: Result{NumStages: puzzle.NumStages()},
+	}
+}
+
+var (
+	// ErrFinished is returned when guessing after the game is over.
+	ErrFinished = errors.New("the game is already over")
+	// ErrUnknownAuthor is returned when guessing someone that isn't one of the puzzle's authors.
+	ErrUnknownAuthor = errors.New("unknown author")
+)
+
+// Puzzle returns the puzzle being played.
+func (s *Session) Puzzle() Puzzle {
+	return s.puzzle
+}
+
+// CurrentStage returns the 0-indexed stage that the next guess is made at. Once the game is over, it's the stage
+// that the last guess was made at.
+func (s *Session) CurrentStage() int {
+	return s.stage
+}
+
+// RevealedClues returns the commits revealed so far.
+func (s *Session) RevealedClues() []Clue {
+	return s.puzzle.Clues(s.stage)
+}
+
+// RevealedHints returns the hints revealed so far.
+func (s *Session) RevealedHints() []Hint {
+	return s.puzzle.Hints(s.stage)
+}
+
+// Finished returns whether the game is over.
+func (s *Session) Finished() bool {
+	return s.finished
+}
+
+// Result returns the result of the guesses made so far.
+func (s *Session) Result() Result {
+	result := s.result
+	result.Guesses = slices.Clone(result.Guesses)
+	result.HintsShown = slices.Clone(result.HintsShown)
+
+	return result
+}
+
+// Guess guesses the author with the given e-mail at the current stage and returns the result so far. The guess was
+// correct if the result is won. After an incorrect guess, the session moves on to the next stage until there aren't
+// any stages left.
+func (s *Session) Guess(email string) (Result, error) {
+	if s.finished {
+		return Result{}, ErrFinished
+	}
+	if _, ok := s.puzzle.allAuthorNames[email]; !ok {
+		return Result{}, fmt.Errorf("%w %q", ErrUnknownAuthor, email)
+	}
+
+	s.result.Guesses = append(s.result.Guesses, email)
+	s.result.HintsShown = append(s.result.HintsShown, s.puzzle.NumHintsShown(s.stage))
+
+	switch {
+	case s.puzzle.IsCorrect(email, s.stage):
+		s.result.Won = true
+		s.finished = true
+	case s.stage == s.puzzle.NumStages()-1:
+		s.finished = true
+	default:
+		s.stage++
+	}
+
+	return s.Result(), nil
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	day, err := DefaultSchedule().ParseDay("2024-06-01")
+	require.NoError(t, err)
+	// The answer is bob@example.com.
+	puzzle := buildGoldenPuzzle(t, goldenHistory(), day)
+
+	t.Run("win", func(t *testing.T) {
+		session := NewSession(puzzle)
+		assert.Equal(t, 0, session.CurrentStage())
+		assert.Len(t, session.RevealedClues(), 1)
+		assert.Empty(t, session.RevealedHints())
+
+		result, err := session.Guess("alice@example.com")
+		require.NoError(t, err)
+		assert.False(t, result.Won)
+		assert.False(t, session.Finished())
+		assert.Equal(t, 1, session.CurrentStage())
+		assert.Len(t, session.RevealedClues(), 2)
+		assert.Len(t, session.RevealedHints(), 1)
+
+		_, err = session.Guess("nobody@example.com")
+		assert.ErrorIs(t, err, ErrUnknownAuthor)
+		assert.Equal(t, 1, session.CurrentStage())
+
+		result, err = session.Guess("bob@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, Result{
+			Guesses:    []string{"alice@example.com", "bob@example.com"},
+			HintsShown: []int{0, 1},
+			Won:        true,
+			NumStages:  4,
+		}, result)
+		assert.True(t, session.Finished())
+		assert.Equal(t, 1, session.CurrentStage())
+
+		_, err = session.Guess("bob@example.com")
+		assert.ErrorIs(t, err, ErrFinished)
+	})
+
+	t.Run("lose", func(t *testing.T) {
+		session := NewSession(puzzle)
+		for range puzzle.NumStages() {
+			require.False(t, session.Finished())
+			_, err := session.Guess("carol@example.com")
+			require.NoError(t, err)
+		}
+
+		result := session.Result()
+		assert.True(t, session.Finished())
+		assert.False(t, result.Won)
+		assert.Equal(t, 0, result.SolvedStage())
+		assert.Equal(t, []int{0, 1, 1, 2}, result.HintsShown)
+		assert.Len(t, session.RevealedClues(), 4)
+	})
+}
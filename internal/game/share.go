@@ -0,0 +1,35 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShareString returns a spoiler-free summary of the result that can be posted for others to see.
+func (r Result) ShareString(puzzleNumber int, repository string) string {
+	var sb strings.Builder
+
+	score := "X"
+	if r.Won {
+		score = fmt.Sprint(r.SolvedStage())
+	}
+	fmt.Fprintf(&sb, "gauthordle #%d %s %s/%d\n", puzzleNumber, repository, score, r.NumStages)
+
+	previousHints := 0
+	for i := range r.Guesses {
+		// Mark the stages where a new hint was revealed.
+		if i < len(r.HintsShown) {
+			sb.WriteString(strings.Repeat("💡", r.HintsShown[i]-previousHints))
+			previousHints = r.HintsShown[i]
+		}
+
+		if r.Won && i == len(r.Guesses)-1 {
+			sb.WriteString("🟩")
+		} else {
+			sb.WriteString("🟥")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult_ShareString(t *testing.T) {
+	tests := []struct {
+		desc   string
+		result Result
+		exp    string
+	}{{
+		desc: "won on first guess",
+		result: Result{
+			Guesses:    []string{"a@example.com"},
+			HintsShown: []int{0},
+			Won:        true,
+			NumStages:  4,
+		},
+		exp: "gauthordle #12 repo 1/4\n🟩\n",
+	}, {
+		desc: "won after hints",
+		result: Result{
+			Guesses:    []string{"a@example.com", "b@example.com", "c@example.com"},
+			HintsShown: []int{0, 1, 1},
+			Won:        true,
+			NumStages:  4,
+		},
+		exp: "gauthordle #12 repo 3/4\n🟥\n💡🟥\n🟩\n",
+	}, {
+		desc: "lost",
+		result: Result{
+			Guesses:    []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"},
+			HintsShown: []int{0, 1, 1, 2},
+			NumStages:  4,
+		},
+		exp: "gauthordle #12 repo X/4\n🟥\n💡🟥\n🟥\n💡🟥\n",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.exp, tc.result.ShareString(12, "repo"))
+		})
+	}
+}
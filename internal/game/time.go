@@ -1,15 +1,80 @@
 package game
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
-func PuzzleTimeRange() (time.Time, time.Time) {
-	// Center the games around Central Time (because that's where I live).
-	gmtNow := time.Now().In(time.FixedZone("CT", 0))
+// puzzleEpoch is the day of the first numbered daily puzzle.
+var puzzleEpoch = time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
 
-	// End with commits from 1.5 years ago so that we likely get authors that people remember.
-	startDate := time.Date(gmtNow.Year()-1, gmtNow.Month()-6, gmtNow.Day(), 0, 0, 0, 0, gmtNow.Location())
-	// End with commits from a week ago to increase the odds that our user will have an up-to-date history.
-	endDate := time.Date(gmtNow.Year(), gmtNow.Month(), gmtNow.Day()-7, 0, 0, 0, 0, gmtNow.Location())
+// Schedule decides when each daily puzzle starts.
+//
+// The default schedule starts a new puzzle at midnight UTC. This is what every version before the schedule was
+// configurable did, so keeping it means that players who don't configure a schedule all still get the same puzzle.
+type Schedule struct {
+	location *time.Location
+	// rolloverHour is the hour of the day in location that the next puzzle starts.
+	rolloverHour int
+}
+
+func DefaultSchedule() Schedule {
+	return Schedule{location: time.UTC}
+}
+
+// NewSchedule builds a schedule from an IANA time zone name (e.g. "America/Chicago") and the hour that new puzzles
+// start at in that zone. An empty time zone is UTC.
+func NewSchedule(timezone string, rolloverHour int) (Schedule, error) {
+	if rolloverHour < 0 || rolloverHour > 23 {
+		return Schedule{}, fmt.Errorf("rollover hour must be between 0 and 23, got %d", rolloverHour)
+	}
+
+	location := time.UTC
+	if timezone != "" {
+		var err error
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+	}
+
+	return Schedule{location: location, rolloverHour: rolloverHour}, nil
+}
+
+// Today returns midnight at the start of the current puzzle day.
+func (s Schedule) Today() time.Time {
+	return s.dayAt(time.Now())
+}
+
+// dayAt returns midnight at the start of the puzzle day that t is in.
+func (s Schedule) dayAt(t time.Time) time.Time {
+	// Shifting back by the rollover hour makes the puzzle day line up with the calendar day.
+	shifted := t.In(s.location).Add(-time.Duration(s.rolloverHour) * time.Hour)
+
+	return time.Date(shifted.Year(), shifted.Month(), shifted.Day(), 0, 0, 0, 0, s.location)
+}
+
+// NextPuzzleTime returns when the next daily puzzle starts.
+func (s Schedule) NextPuzzleTime() time.Time {
+	today := s.Today()
+
+	return time.Date(today.Year(), today.Month(), today.Day()+1, s.rolloverHour, 0, 0, 0, s.location)
+}
+
+// PuzzleDay returns midnight at the start of the day of the given puzzle number.
+func (s Schedule) PuzzleDay(number int) time.Time {
+	return time.Date(puzzleEpoch.Year(), puzzleEpoch.Month(), puzzleEpoch.Day()+number-1, 0, 0, 0, 0, s.location)
+}
+
+// ParseDay parses a day in time.DateOnly format.
+func (s Schedule) ParseDay(day string) (time.Time, error) {
+	return time.ParseInLocation(time.DateOnly, day, s.location)
+}
+
+// PuzzleNumber returns the sequential number of the daily puzzle for the given day. The first puzzle is number 1.
+func PuzzleNumber(day time.Time) int {
+	// Compare calendar days so that the result doesn't depend on the time zone or daylight saving time.
+	dayUTC := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
 
-	return startDate, endDate
+	return int(dayUTC.Sub(puzzleEpoch).Hours()/24) + 1
 }
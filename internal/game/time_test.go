@@ -0,0 +1,72 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPuzzleNumber(t *testing.T) {
+	assert.Equal(t, 1, PuzzleNumber(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 2, PuzzleNumber(time.Date(2024, time.June, 2, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, 367, PuzzleNumber(time.Date(2025, time.June, 2, 0, 0, 0, 0, time.FixedZone("CT", -6*60*60))))
+}
+
+func TestSchedule_PuzzleDay(t *testing.T) {
+	chicago, err := NewSchedule("America/Chicago", 6)
+	require.NoError(t, err)
+
+	for _, schedule := range []Schedule{DefaultSchedule(), chicago} {
+		for _, number := range []int{1, 2, 100, 1000} {
+			assert.Equal(t, number, PuzzleNumber(schedule.PuzzleDay(number)))
+		}
+
+		day, err := schedule.ParseDay("2024-06-10")
+		require.NoError(t, err)
+		assert.Equal(t, schedule.PuzzleDay(10), day)
+	}
+}
+
+func TestSchedule_DayAt(t *testing.T) {
+	chicago, err := NewSchedule("America/Chicago", 6)
+	require.NoError(t, err)
+	location := chicago.location
+
+	tests := []struct {
+		desc     string
+		schedule Schedule
+		at       time.Time
+		exp      time.Time
+	}{{
+		desc:     "default is UTC",
+		schedule: DefaultSchedule(),
+		at:       time.Date(2024, 7, 1, 23, 30, 0, 0, time.FixedZone("CT", -5*60*60)),
+		exp:      time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC),
+	}, {
+		desc:     "before rollover",
+		schedule: chicago,
+		at:       time.Date(2024, 7, 2, 5, 59, 0, 0, location),
+		exp:      time.Date(2024, 7, 1, 0, 0, 0, 0, location),
+	}, {
+		desc:     "after rollover",
+		schedule: chicago,
+		at:       time.Date(2024, 7, 2, 6, 0, 0, 0, location),
+		exp:      time.Date(2024, 7, 2, 0, 0, 0, 0, location),
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.exp, tc.schedule.dayAt(tc.at))
+		})
+	}
+}
+
+func TestNewSchedule_Invalid(t *testing.T) {
+	_, err := NewSchedule("Not/AZone", 0)
+	assert.Error(t, err)
+
+	_, err = NewSchedule("", 24)
+	assert.Error(t, err)
+}
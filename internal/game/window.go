@@ -0,0 +1,143 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+)
+
+// relativeBoundPattern matches a duration before the puzzle day such as "7d", "3mo", or "1y6mo".
+var relativeBoundPattern = regexp.MustCompile(`^(\d+(y|mo|w|d))+$`)
+var relativeBoundPartPattern = regexp.MustCompile(`(\d+)(y|mo|w|d)`)
+
+// WindowBound is one end of the range of commits that puzzles are built from.
+// It's either a duration before the puzzle day, an absolute date, or a git revision.
+type WindowBound struct {
+	years, months, days int
+	// date is an absolute day in time.DateOnly format.
+	date string
+	// revision is a git revision whose commit time is the bound.
+	revision string
+}
+
+// ParseWindowBound parses a window bound. The value is one of:
+//   - A duration before the puzzle day made of years (y), months (mo), weeks (w), and days (d), e.g. "1y6mo".
+//   - A date in YYYY-MM-DD format.
+//   - A git revision such as a tag, branch, or commit hash.
+func ParseWindowBound(value string) (WindowBound, error) {
+	switch {
+	case value == "":
+		return WindowBound{}, errors.New("window bound can't be empty")
+	case relativeBoundPattern.MatchString(value):
+		var result WindowBound
+		for _, part := range relativeBoundPartPattern.FindAllStringSubmatch(value, -1) {
+			n, err := strconv.Atoi(part[1])
+			if err != nil {
+				return WindowBound{}, fmt.Errorf("invalid window bound %q: %w", value, err)
+			}
+
+			switch part[2] {
+			case "y":
+				result.years += n
+			case "mo":
+				result.months += n
+			case "w":
+				result.days += 7 * n
+			case "d":
+				result.days += n
+			}
+		}
+		return result, nil
+	case looksLikeDate(value):
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return WindowBound{}, fmt.Errorf("invalid window bound date %q: %w", value, err)
+		}
+		return WindowBound{date: value}, nil
+	case strings.HasPrefix(value, "-") || strings.ContainsFunc(value, isSpace):
+		// Anything starting with "-" would be treated as an option by git.
+		return WindowBound{}, fmt.Errorf("invalid window bound %q", value)
+	}
+
+	return WindowBound{revision: value}, nil
+}
+
+func looksLikeDate(value string) bool {
+	return len(value) == len(time.DateOnly) && value[4] == '-' && value[7] == '-'
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// Window is the range of commits that puzzles are built from.
+type Window struct {
+	Start WindowBound
+	End   WindowBound
+}
+
+// DefaultWindow uses commits from 1.5 years to a week before the puzzle day.
+// Starting 1.5 years back makes it likely that we get authors that people remember and ending a week back increases
+// the odds that our user will have an up-to-date history.
+func DefaultWindow() Window {
+	return Window{
+		Start: WindowBound{years: 1, months: 6},
+		End:   WindowBound{days: 7},
+	}
+}
+
+// ParseWindow parses the start and end of a window. An empty value keeps the default for that end of the window.
+func ParseWindow(start, end string) (Window, error) {
+	result := DefaultWindow()
+
+	var err error
+	if start != "" {
+		result.Start, err = ParseWindowBound(start)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid window start: %w", err)
+		}
+	}
+	if end != "" {
+		result.End, err = ParseWindowBound(end)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid window end: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// TimeRange returns the range of commits to use for the puzzle on the given day.
+// The history is only used to look up window bounds that are git revisions.
+func (s Schedule) TimeRange(day time.Time, window Window, history git.History) (time.Time, time.Time, error) {
+	start, err := s.boundTime(day, window.Start, history)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid window start: %w", err)
+	}
+	end, err := s.boundTime(day, window.End, history)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid window end: %w", err)
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("window start (%s) must be before window end (%s)", start.Format(time.DateTime), end.Format(time.DateTime))
+	}
+
+	return start, end, nil
+}
+
+func (s Schedule) boundTime(day time.Time, bound WindowBound, history git.History) (time.Time, error) {
+	switch {
+	case bound.revision != "":
+		return history.RevisionTime(bound.revision)
+	case bound.date != "":
+		return s.ParseDay(bound.date)
+	}
+
+	day = day.In(s.location)
+	return time.Date(day.Year()-bound.years, day.Month()-time.Month(bound.months), day.Day()-bound.days, 0, 0, 0, 0, s.location), nil
+}
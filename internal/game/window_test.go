@@ -0,0 +1,78 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_TimeRange(t *testing.T) {
+	schedule := DefaultSchedule()
+	day := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+	release := time.Date(2024, time.May, 4, 12, 30, 0, 0, time.UTC)
+	history := gittest.History{Revisions: map[string]time.Time{"v1.0": release}}
+
+	tests := []struct {
+		desc     string
+		start    string
+		end      string
+		expStart time.Time
+		expEnd   time.Time
+	}{{
+		desc:     "default",
+		expStart: time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC),
+		expEnd:   time.Date(2025, time.March, 24, 0, 0, 0, 0, time.UTC),
+	}, {
+		desc:     "relative",
+		start:    "3mo",
+		end:      "1w2d",
+		expStart: time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC),
+		expEnd:   time.Date(2025, time.March, 22, 0, 0, 0, 0, time.UTC),
+	}, {
+		desc:     "date",
+		start:    "2020-01-15",
+		expStart: time.Date(2020, time.January, 15, 0, 0, 0, 0, time.UTC),
+		expEnd:   time.Date(2025, time.March, 24, 0, 0, 0, 0, time.UTC),
+	}, {
+		desc:     "revision",
+		start:    "v1.0",
+		end:      "0d",
+		expStart: release,
+		expEnd:   day,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			window, err := ParseWindow(tc.start, tc.end)
+			require.NoError(t, err)
+
+			start, end, err := schedule.TimeRange(day, window, history)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expStart, start)
+			assert.Equal(t, tc.expEnd, end)
+		})
+	}
+}
+
+func TestSchedule_TimeRange_Invalid(t *testing.T) {
+	schedule := DefaultSchedule()
+	day := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	for _, bound := range []string{"2024-13-01", "-v1", "two words"} {
+		_, err := ParseWindow(bound, "")
+		assert.Error(t, err, bound)
+	}
+
+	window, err := ParseWindow("1w", "2w")
+	require.NoError(t, err)
+	_, _, err = schedule.TimeRange(day, window, gittest.History{})
+	assert.Error(t, err)
+
+	window, err = ParseWindow("missing", "")
+	require.NoError(t, err)
+	_, _, err = schedule.TimeRange(day, window, gittest.History{})
+	assert.Error(t, err)
+}
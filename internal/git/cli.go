@@ -0,0 +1,261 @@
+package git
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/command"
+)
+
+// CLIHistory reads history by running the git binary.
+type CLIHistory struct {
+	// Runner runs git. If nil, git is run in the current working directory.
+	Runner command.Runner
+}
+
+var _ History = CLIHistory{}
+
+func (h CLIHistory) run(args ...string) (string, error) {
+	if h.Runner == nil {
+		return command.Run("git", args...)
+	}
+
+	return h.Runner.Run("git", args...)
+}
+
+func (h CLIHistory) GetCommits(ref string, start, end time.Time) ([]Commit, error) {
+	// Each commit starts with a record separator and its fields are separated by unit separators.
+	// The numstat output for the commit follows the last field.
+	// The capitalized %aN and %aE apply the repository's .mailmap.
+	const gitLogFormat = "%x1E%H%x1F%aN%x1F%aE%x1F%ad%x1F%cd%x1F%s%x1F%B%x1F"
+	// Git treats bare numbers with more than 8 digits as unix timestamps.
+	since := strconv.FormatInt(start.Unix(), 10)
+	until := strconv.FormatInt(end.Unix(), 10)
+	result, err := h.run(
+		"-c", "core.quotePath=false", "log",
+		"--since="+since,
+		"--until="+until,
+		"--date=raw",
+		"--numstat",
+		// Renames would show up as "old => new" paths, so list them as a deletion and an addition instead.
+		"--no-renames",
+		"--format="+gitLogFormat,
+		cmp.Or(ref, "HEAD"),
+		// Stop git from treating a revision that doesn't exist as a path.
+		"--",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting git logs: %w", err)
+	}
+
+	records := strings.Split(result, "\u001E")
+	// The output starts with a record separator, so the first record is empty.
+	records = records[1:]
+
+	var commits []Commit
+	for _, record := range records {
+		fields := strings.Split(record, "\u001F")
+		if len(fields) != 8 {
+			return nil, errors.New("unexpected response from git log")
+		}
+
+		authorTime, err := parseRawDate(fields[3])
+		if err != nil {
+			return nil, err
+		}
+		commitTime, err := parseRawDate(fields[4])
+		if err != nil {
+			return nil, err
+		}
+		files, err := parseNumstat(fields[7])
+		if err != nil {
+			return nil, err
+		}
+
+		commits = append(commits, Commit{
+			Hash:        fields[0],
+			AuthorName:  fields[1],
+			AuthorEmail: fields[2],
+			AuthorTime:  authorTime,
+			CommitTime:  commitTime,
+			SubjectLine: fields[5],
+			CoAuthors:   parseCoAuthors(fields[6]),
+			Files:       files,
+		})
+	}
+
+	err = h.mapCoAuthors(commits)
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+func (h CLIHistory) RevisionTime(rev string) (time.Time, error) {
+	result, err := h.run("log", "-1", "--date=raw", "--format=%cd", rev, "--")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown revision %q: %w", rev, err)
+	}
+
+	return parseRawDate(strings.TrimSpace(result))
+}
+
+func (h CLIHistory) CommitDetails(commit Commit) (CommitDetails, error) {
+	result, err := h.run(
+		"-c", "core.quotePath=false", "show",
+		"--format=%B%x1F",
+		"--patch",
+		"--unified=0",
+		"--no-renames",
+		"--no-color",
+		"--no-ext-diff",
+		// Merge commits are left out of the history's files, so leave them out of the patch too.
+		"--diff-merges=off",
+		// Override any configured prefixes.
+		"--src-prefix=a/",
+		"--dst-prefix=b/",
+		commit.Hash,
+		"--",
+	)
+	if err != nil {
+		return CommitDetails{}, fmt.Errorf("error when showing commit %s: %w", commit.Hash, err)
+	}
+
+	message, patch, ok := strings.Cut(result, "\u001F")
+	if !ok {
+		return CommitDetails{}, errors.New("unexpected response from git show")
+	}
+
+	return CommitDetails{Body: messageBody(message), Patch: parsePatch(patch)}, nil
+}
+
+// parsePatch keeps only the file names and changed lines of a diff, which is the format of CommitDetails.Patch.
+func parsePatch(s string) string {
+	var result strings.Builder
+	// The file names are in the header of each file's diff, which lasts until the first hunk.
+	inHeader := false
+	for _, line := range strings.Split(s, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			inHeader = true
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+		case inHeader && (strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ")):
+			// Git ends names that contain spaces with a tab.
+			result.WriteString(strings.TrimSuffix(line, "\t") + "\n")
+		case !inHeader && (strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+")):
+			result.WriteString(line + "\n")
+		}
+	}
+
+	return result.String()
+}
+
+// mapCoAuthors applies the repository's mailmap to the co-authors of the commits.
+// Git only does this automatically for the author and committer.
+func (h CLIHistory) mapCoAuthors(commits []Commit) error {
+	var identities []string
+	seen := map[string]struct{}{}
+	for _, commit := range commits {
+		for _, coAuthor := range commit.CoAuthors {
+			identity := formatIdentity(coAuthor)
+			if _, ok := seen[identity]; !ok {
+				seen[identity] = struct{}{}
+				identities = append(identities, identity)
+			}
+		}
+	}
+	if len(identities) == 0 {
+		return nil
+	}
+
+	result, err := h.run(append([]string{"check-mailmap"}, identities...)...)
+	if err != nil {
+		return fmt.Errorf("error when applying mailmap: %w", err)
+	}
+
+	mapped := strings.Split(strings.TrimSuffix(result, "\n"), "\n")
+	if len(mapped) != len(identities) {
+		return errors.New("unexpected response from git check-mailmap")
+	}
+	mapping := map[string]Identity{}
+	for i, identity := range identities {
+		name, email, _, ok := cutIdentity(mapped[i])
+		if !ok {
+			return errors.New("unexpected response from git check-mailmap")
+		}
+		mapping[identity] = Identity{Name: name, Email: email}
+	}
+
+	for _, commit := range commits {
+		for i, coAuthor := range commit.CoAuthors {
+			commit.CoAuthors[i] = mapping[formatIdentity(coAuthor)]
+		}
+	}
+
+	return nil
+}
+
+func formatIdentity(identity Identity) string {
+	if identity.Name == "" {
+		return "<" + identity.Email + ">"
+	}
+
+	return identity.Name + " <" + identity.Email + ">"
+}
+
+// parseRawDate parses a date in git's raw "<unix seconds> <+hhmm>" format.
+func parseRawDate(s string) (time.Time, error) {
+	seconds, zone, _ := strings.Cut(s, " ")
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected date %q from git log", s)
+	}
+
+	return time.Unix(unix, 0).In(parseTimezone(zone)), nil
+}
+
+// parseNumstat parses the "<additions>\t<deletions>\t<path>" lines output by "git log --numstat".
+func parseNumstat(s string) ([]FileChange, error) {
+	var result []FileChange
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected numstat line %q from git log", line)
+		}
+
+		change := FileChange{Path: fields[2]}
+		if fields[0] == "-" && fields[1] == "-" {
+			change.Binary = true
+		} else {
+			var err1, err2 error
+			change.Additions, err1 = strconv.Atoi(fields[0])
+			change.Deletions, err2 = strconv.Atoi(fields[1])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("unexpected numstat line %q from git log", line)
+			}
+		}
+
+		// Paths with unusual characters are quoted even with core.quotePath disabled.
+		if strings.HasPrefix(change.Path, `"`) {
+			path, err := strconv.Unquote(change.Path)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected numstat path %q from git log", change.Path)
+			}
+			change.Path = path
+		}
+
+		result = append(result, change)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner returns canned output for each git subcommand.
+type fakeRunner map[string]string
+
+func (r fakeRunner) Run(cmd string, args ...string) (string, error) {
+	for _, arg := range args {
+		if output, ok := r[arg]; ok {
+			return output, nil
+		}
+	}
+
+	return "", fmt.Errorf("unexpected command %s %s", cmd, strings.Join(args, " "))
+}
+
+func TestCLIHistory_GetCommits(t *testing.T) {
+	runner := fakeRunner{
+		"log": "\x1Eabc123\x1FAlice\x1Falice@example.com\x1F1700000000 -0600\x1F1700000100 +0000\x1FAdd a feature\x1F" +
+			"Add a feature\n\nCo-authored-by: Bob <bob@example.com>\n\x1F\n\n3\t1\tmain.go\n-\t-\timage.png\n0\t2\t\"tab\\there.txt\"\n",
+		"check-mailmap": "Robert <robert@example.com>\n",
+	}
+
+	commits, err := CLIHistory{Runner: runner}.GetCommits("", time.Unix(1e9, 0), time.Unix(2e9, 0))
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+
+	assert.Equal(t, Commit{
+		Hash:        "abc123",
+		AuthorName:  "Alice",
+		AuthorEmail: "alice@example.com",
+		AuthorTime:  time.Unix(1700000000, 0).In(parseTimezone("-0600")),
+		CommitTime:  time.Unix(1700000100, 0).In(parseTimezone("+0000")),
+		SubjectLine: "Add a feature",
+		CoAuthors:   []Identity{{Name: "Robert", Email: "robert@example.com"}},
+		Files: []FileChange{
+			{Path: "main.go", Additions: 3, Deletions: 1},
+			{Path: "image.png", Binary: true},
+			{Path: "tab\there.txt", Deletions: 2},
+		},
+	}, commits[0])
+}
+
+func TestCLIHistory_GetCommits_UnexpectedOutput(t *testing.T) {
+	_, err := CLIHistory{Runner: fakeRunner{"log": "\x1Eabc123\x1FAlice"}}.GetCommits("", time.Unix(1e9, 0), time.Unix(2e9, 0))
+	assert.Error(t, err)
+}
+
+func TestCLIHistory_CommitDetails(t *testing.T) {
+	runner := fakeRunner{
+		"show": "Add a feature\n\nIt's a good one.\n\nSigned-off-by: Alice <alice@example.com>\n\x1F\n" +
+			"diff --git a/main.go b/main.go\nindex 1234567..89abcde 100644\n--- a/main.go\n+++ b/main.go\n" +
+			"@@ -3 +3,2 @@ func main() {\n--- a removed line that looks like a header\n+\tfmt.Println(\"hi\")\n+}\n" +
+			"\\ No newline at end of file\n" +
+			"diff --git a/image.png b/image.png\nnew file mode 100644\nindex 0000000..1234567\nBinary files /dev/null and b/image.png differ\n" +
+			"diff --git a/old notes.txt b/old notes.txt\ndeleted file mode 100644\nindex 1234567..0000000\n--- a/old notes.txt\t\n+++ /dev/null\n" +
+			"@@ -1 +0,0 @@\n-notes\n",
+	}
+
+	details, err := CLIHistory{Runner: runner}.CommitDetails(Commit{Hash: "abc123"})
+	require.NoError(t, err)
+	assert.Equal(t, CommitDetails{
+		Body: "It's a good one.\n\nSigned-off-by: Alice <alice@example.com>",
+		Patch: "--- a/main.go\n+++ b/main.go\n--- a removed line that looks like a header\n+\tfmt.Println(\"hi\")\n+}\n" +
+			"--- a/old notes.txt\n+++ /dev/null\n-notes\n",
+	}, details)
+
+	_, err = CLIHistory{Runner: fakeRunner{"show": "no separator"}}.CommitDetails(Commit{Hash: "abc123"})
+	assert.Error(t, err)
+}
@@ -1,61 +1,87 @@
 package git
 
 import (
-	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/josephnaberhaus/gauthordle/internal/command"
 )
 
 type Commit struct {
+	Hash        string
 	AuthorName  string
 	AuthorEmail string
+	// AuthorTime is when the commit was authored, in the author's time zone.
+	AuthorTime time.Time
+	// CommitTime is when the commit was committed, in the committer's time zone.
+	CommitTime  time.Time
 	SubjectLine string
+	// CoAuthors are the people credited by "Co-authored-by" trailers in the commit message.
+	CoAuthors []Identity
+	// Files are the files changed by the commit. This is empty for merge commits.
+	Files []FileChange
+	// Repository is the name of the repository the commit is from when commits from multiple repositories are mixed.
+	// It's empty otherwise.
+	Repository string
 }
 
-func GetCommits(start, end time.Time) ([]Commit, error) {
-	const gitLogFormat = "%an\u001F%ae\u001F%s\u001E"
-	result, err := command.Run("git", "log", "--since="+start.Format(time.DateOnly), "--until="+end.Format(time.DateOnly), "--format="+gitLogFormat)
-	if err != nil {
-		return nil, fmt.Errorf("error when getting git logs: %w", err)
-	}
-
-	// The rest of the code is written with the assumption that there are no new lines.
-	result = strings.ReplaceAll(result, "\n", "")
+// Identity is a person's name and e-mail.
+type Identity struct {
+	Name  string
+	Email string
+}
 
-	records := strings.Split(result, "\u001E")
-	if len(records) == 0 {
-		return nil, errors.New("unexpected response from git log")
-	}
-	// The last record will be an empty line.
-	records = records[:len(records)-1]
-
-	var commits []Commit
-	for _, record := range records {
-		fields := strings.Split(record, "\u001F")
-		if len(fields) != 3 {
-			return nil, errors.New("unexpected response from git log")
-		}
+// FileChange is a file changed by a commit along with how many lines were changed.
+type FileChange struct {
+	Path      string
+	Additions int
+	Deletions int
+	// Binary is true if the file isn't text, in which case the line counts are zero.
+	Binary bool
+}
 
-		commits = append(commits, Commit{
-			AuthorName:  fields[0],
-			AuthorEmail: fields[1],
-			SubjectLine: fields[2],
-		})
-	}
+// CommitDetails are the parts of a commit that are too expensive to read for every commit in the history.
+type CommitDetails struct {
+	// Body is the commit message after the subject line, including any trailers.
+	Body string
+	// Patch lists the lines changed in each text file, in the same order as Commit.Files. Each file starts with
+	// "--- a/<path>" and "+++ b/<path>" lines (or /dev/null for added and deleted files) followed by the removed lines
+	// prefixed by "-" and the added lines prefixed by "+". Unchanged lines, hunk headers, and binary files are left out.
+	// It's empty for merge commits. When there's more than one smallest set of changed lines, the backends may not
+	// pick the same one.
+	Patch string
+}
 
-	return commits, nil
+// History is a source of commits for a repository.
+type History interface {
+	// GetCommits gets the commits reachable from ref that were committed between start and end.
+	// An empty ref is HEAD. Commits are returned newest first.
+	GetCommits(ref string, start, end time.Time) ([]Commit, error)
+	// RevisionTime returns when the commit that a revision (a hash, branch, or tag) points to was committed.
+	RevisionTime(rev string) (time.Time, error)
+	// CommitDetails reads the details of a commit returned by GetCommits.
+	CommitDetails(commit Commit) (CommitDetails, error)
 }
 
-// GetFilesChangedForAuthor gets all the files touched be the given user.
-// The returned list can contain duplicates.
-func GetFilesChangedForAuthor(authorEmail string) ([]string, error) {
-	result, err := command.Run("git", "log", "--author="+authorEmail, "--name-only", "--format=")
-	if err != nil {
-		return nil, fmt.Errorf("error when getting files : %w", err)
+const (
+	// BackendNative reads history directly from the .git directory.
+	BackendNative = "native"
+	// BackendCLI reads history by running the git binary.
+	BackendCLI = "git"
+)
+
+// NewHistory reads the history of the repository using the given backend. An empty backend selects the native one.
+func NewHistory(repo *Repository, backend string) (History, error) {
+	switch backend {
+	case "", BackendNative:
+		return NewNativeHistory(repo), nil
+	case BackendCLI:
+		if !IsGitInstalled() {
+			return nil, fmt.Errorf("git must be installed to use the %q history backend", BackendCLI)
+		}
+
+		return CLIHistory{Runner: command.ExecRunner{Dir: repo.Path()}}, nil
 	}
 
-	return strings.Split(result, "\n"), nil
+	return nil, fmt.Errorf("unknown history backend %q", backend)
 }
@@ -0,0 +1,241 @@
+package git
+
+import (
+	"bytes"
+	"hash/maphash"
+	"slices"
+	"strings"
+)
+
+// binaryCheckSize is how many leading bytes are checked for NULs when deciding if a file is binary, the same as git.
+const binaryCheckSize = 8000
+
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binaryCheckSize)], 0) >= 0
+}
+
+// countLineChanges counts the lines added and removed between two versions of a file using a minimal line diff. Files
+// with more than maxLineEdits changes are counted as if every line between the first and last change was replaced.
+func countLineChanges(oldData, newData []byte) (additions, deletions int) {
+	seed := maphash.MakeSeed()
+	oldLines := hashLines(oldData, seed)
+	newLines := hashLines(newData, seed)
+
+	// Lines at the start and end are usually unchanged, so trim them before doing the more expensive diff.
+	for len(oldLines) > 0 && len(newLines) > 0 && oldLines[0] == newLines[0] {
+		oldLines, newLines = oldLines[1:], newLines[1:]
+	}
+	for len(oldLines) > 0 && len(newLines) > 0 && oldLines[len(oldLines)-1] == newLines[len(newLines)-1] {
+		oldLines, newLines = oldLines[:len(oldLines)-1], newLines[:len(newLines)-1]
+	}
+
+	edits, ok := editDistance(oldLines, newLines)
+	if !ok {
+		return len(newLines), len(oldLines)
+	}
+
+	// Every edit is either an addition or deletion, and the difference between those is the change in length.
+	growth := len(newLines) - len(oldLines)
+
+	return (edits + growth) / 2, (edits - growth) / 2
+}
+
+func hashLines(data []byte, seed maphash.Seed) []uint64 {
+	var result []uint64
+	for _, line := range splitLines(data) {
+		result = append(result, maphash.Bytes(seed, line))
+	}
+
+	return result
+}
+
+// splitLines splits data into lines. Each line includes its newline so that a missing newline at the end of the file
+// counts as a change, like git.
+func splitLines(data []byte) [][]byte {
+	var result [][]byte
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end++
+		}
+
+		result = append(result, data[:end])
+		data = data[end:]
+	}
+
+	return result
+}
+
+// editDistance returns the minimum number of line insertions and deletions to turn a into b.
+// This is the greedy algorithm from Myers' "An O(ND) Difference Algorithm and Its Variations". It gives up if more than
+// maxLineEdits edits are needed.
+func editDistance(a, b []uint64) (int, bool) {
+	n, m := len(a), len(b)
+	maxEdits := min(n+m, maxLineEdits)
+
+	// furthest[k+offset] is the furthest x reached on diagonal k = x - y.
+	offset := maxEdits + 1
+	furthest := make([]int, 2*maxEdits+3)
+	for d := 0; d <= maxEdits; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && furthest[offset+k-1] < furthest[offset+k+1]) {
+				x = furthest[offset+k+1]
+			} else {
+				x = furthest[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			furthest[offset+k] = x
+
+			if x >= n && y >= m {
+				return d, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// maxLineEdits bounds the time and memory used to diff a file, since the diff takes time proportional to the length of
+// the file times the number of changes. Files with more changes than this are treated as if every line between the
+// first and last change was replaced.
+const maxLineEdits = 1000
+
+// writePatch appends the lines changed between two versions of a file in the format of CommitDetails.Patch.
+// Nothing is written if no lines changed.
+func writePatch(patch *strings.Builder, change treeChange, oldData, newData []byte) {
+	oldLines, newLines := splitLines(oldData), splitLines(newData)
+	seed := maphash.MakeSeed()
+	deleted, added := lineEdits(hashLines(oldData, seed), hashLines(newData, seed))
+	if !slices.Contains(deleted, true) && !slices.Contains(added, true) {
+		return
+	}
+
+	oldName, newName := "/dev/null", "/dev/null"
+	if change.old != nil {
+		oldName = "a/" + change.path
+	}
+	if change.new != nil {
+		newName = "b/" + change.path
+	}
+	patch.WriteString("--- " + oldName + "\n+++ " + newName + "\n")
+
+	writeLine := func(prefix string, line []byte) {
+		patch.WriteString(prefix)
+		patch.Write(bytes.TrimSuffix(line, []byte("\n")))
+		patch.WriteString("\n")
+	}
+
+	// Like a hunk in a unified diff, each run of changes lists its removed lines before its added lines.
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		if i < len(oldLines) && j < len(newLines) && !deleted[i] && !added[j] {
+			i++
+			j++
+			continue
+		}
+
+		for ; i < len(oldLines) && deleted[i]; i++ {
+			writeLine("-", oldLines[i])
+		}
+		for ; j < len(newLines) && added[j]; j++ {
+			writeLine("+", newLines[j])
+		}
+	}
+}
+
+// lineEdits finds a minimal set of lines to delete from a and add from b to turn a into b. The results say whether
+// each line is deleted or added.
+func lineEdits(a, b []uint64) (deleted, added []bool) {
+	deleted, added = make([]bool, len(a)), make([]bool, len(b))
+
+	// Unchanged lines at the start and end don't need to go through the more expensive diff.
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		start++
+	}
+	endA, endB := len(a), len(b)
+	for endA > start && endB > start && a[endA-1] == b[endB-1] {
+		endA--
+		endB--
+	}
+
+	trace, ok := editTrace(a[start:endA], b[start:endB])
+	if !ok {
+		for i := start; i < endA; i++ {
+			deleted[i] = true
+		}
+		for j := start; j < endB; j++ {
+			added[j] = true
+		}
+		return deleted, added
+	}
+
+	// Walk back through the trace from the end to find the edit made at each step.
+	x, y := endA-start, endB-start
+	for d := len(trace) - 1; d > 0; d-- {
+		furthest := trace[d]
+		// furthest[k+d] is the furthest x reached on diagonal k after d-1 edits.
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && furthest[k-1+d] < furthest[k+1+d]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := furthest[prevK+d]
+		prevY := prevX - prevK
+
+		if prevK == k+1 {
+			added[start+prevY] = true
+		} else {
+			deleted[start+prevX] = true
+		}
+		x, y = prevX, prevY
+	}
+
+	return deleted, added
+}
+
+// editTrace runs the same algorithm as editDistance, but also returns the furthest x reached on each diagonal before
+// each step so that the edits can be recovered. Entry d of the trace covers the diagonals -d to d. It gives up if
+// more than maxLineEdits edits are needed.
+func editTrace(a, b []uint64) ([][]int, bool) {
+	n, m := len(a), len(b)
+	maxEdits := min(n+m, maxLineEdits)
+	offset := maxEdits + 1
+	furthest := make([]int, 2*maxEdits+3)
+
+	var trace [][]int
+	for d := 0; d <= maxEdits; d++ {
+		trace = append(trace, append([]int(nil), furthest[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && furthest[offset+k-1] < furthest[offset+k+1]) {
+				x = furthest[offset+k+1]
+			} else {
+				x = furthest[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			furthest[offset+k] = x
+
+			if x >= n && y >= m {
+				return trace, true
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,39 @@
+package git
+
+import (
+	"fmt"
+	"hash/maphash"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountLineChanges(t *testing.T) {
+	additions, deletions := countLineChanges([]byte("a\nb\nc\nd\n"), []byte("a\nB\nc\nd\ne\n"))
+	assert.Equal(t, 2, additions)
+	assert.Equal(t, 1, deletions)
+
+	additions, deletions = countLineChanges(nil, []byte("a\nb"))
+	assert.Equal(t, 2, additions)
+	assert.Equal(t, 0, deletions)
+
+	// Rewriting a large file gives up on the diff rather than taking seconds, and counts the changed middle as
+	// replaced.
+	seed := maphash.MakeSeed()
+	var oldFile, newFile strings.Builder
+	oldFile.WriteString("header\n")
+	newFile.WriteString("header\n")
+	for i := range 40000 {
+		fmt.Fprintf(&oldFile, "old %d\n", i)
+		fmt.Fprintf(&newFile, "new %d\n", i)
+	}
+	oldFile.WriteString("footer\n")
+	newFile.WriteString("footer\n")
+
+	_, ok := editDistance(hashLines([]byte(oldFile.String()), seed), hashLines([]byte(newFile.String()), seed))
+	assert.False(t, ok)
+	additions, deletions = countLineChanges([]byte(oldFile.String()), []byte(newFile.String()))
+	assert.Equal(t, 40000, additions)
+	assert.Equal(t, 40000, deletions)
+}
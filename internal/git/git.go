@@ -11,8 +11,9 @@ func IsGitInstalled() bool {
 	return true
 }
 
-func IsInGitRepo() bool {
-	_, err := command.Run("git", "status")
+// IsInGitRepo returns whether dir is inside a git repository. An empty dir is the current working directory.
+func IsInGitRepo(dir string) bool {
+	_, err := command.ExecRunner{Dir: dir}.Run("git", "status")
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,167 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitConfig is a parsed git config file. It maps "section.subsection.key" names to every value set for them.
+// Section and key names are lower-cased since they're case-insensitive. Subsections are case-sensitive.
+type gitConfig map[string][]string
+
+// parseGitConfig parses the git config file format described in git-config(1).
+// Include directives aren't followed.
+func parseGitConfig(r io.Reader) (gitConfig, error) {
+	result := gitConfig{}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		// A trailing backslash continues the value on the next line.
+		for strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) && scanner.Scan() {
+			lineNumber++
+			line = line[:len(line)-1] + scanner.Text()
+		}
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			header, rest, ok := strings.Cut(line[1:], "]")
+			if !ok || !isConfigComment(rest) {
+				return nil, fmt.Errorf("invalid section header on line %d of git config", lineNumber)
+			}
+			section = parseSectionHeader(header)
+			continue
+		}
+
+		if section == "" {
+			return nil, fmt.Errorf("key outside of a section on line %d of git config", lineNumber)
+		}
+
+		key, value, hasValue := strings.Cut(line, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !hasValue {
+			// A key without a value is a boolean that's true.
+			key, _, _ = strings.Cut(key, " ")
+			value = "true"
+		} else {
+			var err error
+			value, err = parseConfigValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value on line %d of git config: %w", lineNumber, err)
+			}
+		}
+
+		name := section + "." + key
+		result[name] = append(result[name], value)
+	}
+
+	return result, scanner.Err()
+}
+
+// parseSectionHeader returns the prefix of the names of the keys in a section.
+// Headers are either `section "subsection"` or the deprecated `section.subsection`.
+func parseSectionHeader(header string) string {
+	name, subsection, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return strings.ToLower(name)
+	}
+
+	subsection = strings.TrimSpace(subsection)
+	subsection = strings.TrimSuffix(strings.TrimPrefix(subsection, `"`), `"`)
+	subsection = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(subsection)
+
+	return strings.ToLower(name) + "." + subsection
+}
+
+func isConfigComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s[0] == '#' || s[0] == ';'
+}
+
+// parseConfigValue unquotes a value and strips its trailing comment.
+func parseConfigValue(value string) (string, error) {
+	var result strings.Builder
+	inQuotes := false
+	// pendingSpace holds unquoted whitespace, which is dropped if it's at the end of the value.
+	pendingSpace := ""
+	value = strings.TrimSpace(value)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '\\':
+			i++
+			if i == len(value) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			switch value[i] {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case '"', '\\':
+				c = value[i]
+			default:
+				return "", fmt.Errorf("unknown escape sequence \\%c", value[i])
+			}
+			result.WriteString(pendingSpace)
+			pendingSpace = ""
+			result.WriteByte(c)
+		case !inQuotes && (c == '#' || c == ';'):
+			return result.String(), nil
+		case !inQuotes && (c == ' ' || c == '\t'):
+			pendingSpace += string(c)
+		default:
+			result.WriteString(pendingSpace)
+			pendingSpace = ""
+			result.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return "", fmt.Errorf("unterminated quote")
+	}
+
+	return result.String(), nil
+}
+
+// get returns the last value set for a key, which is the one git uses.
+func (c gitConfig) get(section, subsection, key string) string {
+	name := strings.ToLower(section) + "."
+	if subsection != "" {
+		name += subsection + "."
+	}
+	name += strings.ToLower(key)
+
+	values := c[name]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[len(values)-1]
+}
+
+// config reads the repository's config file.
+func (r *Repository) config() (gitConfig, error) {
+	f, err := os.Open(filepath.Join(r.commonDir, "config"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return gitConfig{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseGitConfig(f)
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitConfig(t *testing.T) {
+	input := `# A comment
+[core]
+	bare = false
+	logAllRefUpdates
+[remote "origin"]
+	url = https://example.com/repo.git ; the URL
+	fetch = +refs/heads/*:refs/remotes/origin/*
+[branch "Main"]
+	remote = origin
+	merge = refs/heads/main
+[Branch.Legacy]
+	remote = "up stream" # quoted
+[user]
+	name = "Joe \"J\" Smith"
+	email = joe@example.com \
+		# continued
+	name = Joe Smith
+`
+
+	cfg, err := parseGitConfig(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, "false", cfg.get("core", "", "bare"))
+	assert.Equal(t, "true", cfg.get("core", "", "logallrefupdates"))
+	assert.Equal(t, "https://example.com/repo.git", cfg.get("remote", "origin", "url"))
+	assert.Equal(t, "origin", cfg.get("branch", "Main", "remote"))
+	assert.Equal(t, "", cfg.get("branch", "main", "remote"))
+	assert.Equal(t, "up stream", cfg.get("branch", "legacy", "remote"))
+	assert.Equal(t, []string{`Joe "J" Smith`, "Joe Smith"}, cfg["user.name"])
+	assert.Equal(t, "Joe Smith", cfg.get("USER", "", "Name"))
+	assert.Equal(t, "joe@example.com", cfg.get("user", "", "email"))
+}
+
+func TestParseGitConfig_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"key = value",
+		"[core\nbare = false",
+		"[core]\nname = \"unterminated",
+	} {
+		_, err := parseGitConfig(strings.NewReader(input))
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,168 @@
+package git
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mailmap maps the author names and e-mails recorded in commits to canonical ones.
+// See gitmailmap(5) for the file format.
+type Mailmap struct {
+	// entries is a map from the lower-cased commit e-mail to the entries for that e-mail.
+	entries map[string][]mailmapEntry
+}
+
+type mailmapEntry struct {
+	// commitName is the lower-cased name this entry applies to. If empty, it applies to any name.
+	commitName  string
+	properName  string
+	properEmail string
+}
+
+// ParseMailmap parses the contents of a .mailmap file.
+// Like git, malformed lines are ignored.
+func ParseMailmap(r io.Reader) (Mailmap, error) {
+	result := Mailmap{entries: map[string][]mailmapEntry{}}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		firstName, firstEmail, rest, ok := cutIdentity(line)
+		if !ok {
+			continue
+		}
+
+		secondName, secondEmail, _, ok := cutIdentity(rest)
+		if !ok {
+			// "Proper Name <commit@email>" only replaces the name.
+			result.add(firstEmail, mailmapEntry{properName: firstName})
+			continue
+		}
+
+		// "[Proper Name] <proper@email> [Commit Name] <commit@email>"
+		result.add(secondEmail, mailmapEntry{
+			commitName:  strings.ToLower(secondName),
+			properName:  firstName,
+			properEmail: firstEmail,
+		})
+	}
+
+	return result, scanner.Err()
+}
+
+// cutIdentity cuts a "[Name] <email>" pair off the front of s.
+func cutIdentity(s string) (name, email, rest string, ok bool) {
+	emailStart := strings.IndexByte(s, '<')
+	if emailStart < 0 {
+		return "", "", "", false
+	}
+	emailEnd := strings.IndexByte(s[emailStart:], '>')
+	if emailEnd < 0 {
+		return "", "", "", false
+	}
+	emailEnd += emailStart
+
+	name = strings.TrimSpace(s[:emailStart])
+	email = strings.TrimSpace(s[emailStart+1 : emailEnd])
+	return name, email, s[emailEnd+1:], true
+}
+
+func (m Mailmap) add(commitEmail string, entry mailmapEntry) {
+	key := strings.ToLower(commitEmail)
+	m.entries[key] = append(m.entries[key], entry)
+}
+
+// Resolve returns the canonical name and e-mail for an identity found in a commit.
+func (m Mailmap) Resolve(name, email string) (string, string) {
+	entries := m.entries[strings.ToLower(email)]
+
+	// Entries that match both the name and e-mail take priority over those that only match the e-mail.
+	// Later entries take priority over earlier ones.
+	var match *mailmapEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.commitName == "" && (match == nil || match.commitName == "") {
+			match = entry
+		}
+		if entry.commitName != "" && entry.commitName == strings.ToLower(name) {
+			match = entry
+		}
+	}
+	if match == nil {
+		return name, email
+	}
+
+	if match.properName != "" {
+		name = match.properName
+	}
+	if match.properEmail != "" {
+		email = match.properEmail
+	}
+
+	return name, email
+}
+
+// Mailmap reads the repository's .mailmap file. For bare repositories, it's read from HEAD like git does.
+// An empty mailmap is returned if the repository doesn't have one.
+func (r *Repository) Mailmap() (Mailmap, error) {
+	if r.workTree != "" {
+		f, err := os.Open(filepath.Join(r.workTree, ".mailmap"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return ParseMailmap(strings.NewReader(""))
+			}
+			return Mailmap{}, err
+		}
+		defer f.Close()
+
+		return ParseMailmap(f)
+	}
+
+	contents, err := r.readFileAtHead(".mailmap")
+	if err != nil {
+		return Mailmap{}, err
+	}
+
+	return ParseMailmap(strings.NewReader(string(contents)))
+}
+
+// readFileAtHead reads a top-level file from the tree of the HEAD commit. Nil is returned if it doesn't exist.
+func (r *Repository) readFileAtHead(name string) ([]byte, error) {
+	head, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := r.objects.readTyped(head, objectCommit)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := parseCommit(head, data)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = r.objects.readTyped(commit.tree, objectTree)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseTree(commit.tree, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.name == name && !entry.isTree() {
+			return r.objects.readTyped(entry.hash, objectBlob)
+		}
+	}
+
+	return nil, nil
+}
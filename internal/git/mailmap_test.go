@@ -0,0 +1,74 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailmap_Resolve(t *testing.T) {
+	const mailmap = `
+# A comment
+Joe Smith <joe@example.com>
+<jane@example.com> <jane@personal.com>
+Jane Doe <jane@example.com> <JANE@old.com>
+Bob Barker <bob@example.com> bobby <bob@shared.com>
+Robert Barker <robert@example.com> <bob@shared.com>
+not a valid line
+`
+
+	m, err := ParseMailmap(strings.NewReader(mailmap))
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc              string
+		name, email       string
+		expName, expEmail string
+	}{{
+		desc:     "unmapped identity is unchanged",
+		name:     "Someone",
+		email:    "someone@example.com",
+		expName:  "Someone",
+		expEmail: "someone@example.com",
+	}, {
+		desc:     "name only mapping",
+		name:     "joe",
+		email:    "joe@example.com",
+		expName:  "Joe Smith",
+		expEmail: "joe@example.com",
+	}, {
+		desc:     "email only mapping",
+		name:     "Jane",
+		email:    "jane@personal.com",
+		expName:  "Jane",
+		expEmail: "jane@example.com",
+	}, {
+		desc:     "email is case insensitive",
+		name:     "Jane",
+		email:    "jane@OLD.com",
+		expName:  "Jane Doe",
+		expEmail: "jane@example.com",
+	}, {
+		desc:     "commit name match takes priority",
+		name:     "Bobby",
+		email:    "bob@shared.com",
+		expName:  "Bob Barker",
+		expEmail: "bob@example.com",
+	}, {
+		desc:     "falls back to e-mail only entry",
+		name:     "Somebody else",
+		email:    "bob@shared.com",
+		expName:  "Robert Barker",
+		expEmail: "robert@example.com",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			name, email := m.Resolve(tc.name, tc.email)
+			assert.Equal(t, tc.expName, name)
+			assert.Equal(t, tc.expEmail, email)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MultiHistory merges the histories of several repositories into one.
+type MultiHistory struct {
+	// repos is sorted by name so that the merged history doesn't depend on the order the repositories were given in.
+	repos []namedHistory
+}
+
+type namedHistory struct {
+	name    string
+	history History
+}
+
+var _ History = (*MultiHistory)(nil)
+
+// NewMultiHistory merges histories keyed by the name of their repository.
+func NewMultiHistory(histories map[string]History) *MultiHistory {
+	result := &MultiHistory{}
+	for name, history := range histories {
+		result.repos = append(result.repos, namedHistory{name: name, history: history})
+	}
+	slices.SortFunc(result.repos, func(a, b namedHistory) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return result
+}
+
+// GetCommits gets the commits reachable from ref in every repository, with Repository set to the repository's name.
+// Commits are returned newest first. Ties are broken by repository name and then hash so that the order is stable.
+func (h *MultiHistory) GetCommits(ref string, start, end time.Time) ([]Commit, error) {
+	var result []Commit
+	for _, repo := range h.repos {
+		commits, err := repo.history.GetCommits(ref, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("error when reading the history of %s: %w", repo.name, err)
+		}
+
+		for i := range commits {
+			commits[i].Repository = repo.name
+		}
+		result = append(result, commits...)
+	}
+
+	slices.SortStableFunc(result, func(a, b Commit) int {
+		return cmp.Or(
+			b.CommitTime.Compare(a.CommitTime),
+			strings.Compare(a.Repository, b.Repository),
+			strings.Compare(a.Hash, b.Hash),
+		)
+	})
+
+	return result, nil
+}
+
+// RevisionTime returns the commit time of the revision in the first repository, by name, that has it.
+func (h *MultiHistory) RevisionTime(rev string) (time.Time, error) {
+	for _, repo := range h.repos {
+		t, err := repo.history.RevisionTime(rev)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown revision %q in every repository", rev)
+}
+
+// CommitDetails reads the details of the commit from the repository it's from.
+func (h *MultiHistory) CommitDetails(commit Commit) (CommitDetails, error) {
+	for _, repo := range h.repos {
+		if repo.name == commit.Repository {
+			return repo.history.CommitDetails(commit)
+		}
+	}
+
+	return CommitDetails{}, fmt.Errorf("unknown repository %q", commit.Repository)
+}
@@ -0,0 +1,58 @@
+package git_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiHistory(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	api := gittest.History{
+		Commits: []git.Commit{
+			{Hash: "a2", CommitTime: base.Add(2 * time.Hour)},
+			{Hash: "a1", CommitTime: base},
+		},
+		Revisions: map[string]time.Time{"v1": base},
+	}
+	web := gittest.History{
+		Commits: []git.Commit{
+			{Hash: "w2", CommitTime: base.Add(3 * time.Hour)},
+			{Hash: "w1", CommitTime: base},
+		},
+		Revisions: map[string]time.Time{"v1": base.Add(time.Hour), "v2": base.Add(3 * time.Hour)},
+		Details:   map[string]git.CommitDetails{"w1": {Body: "The body of w1."}},
+	}
+	expected := []git.Commit{
+		{Hash: "w2", CommitTime: base.Add(3 * time.Hour), Repository: "web"},
+		{Hash: "a2", CommitTime: base.Add(2 * time.Hour), Repository: "api"},
+		{Hash: "a1", CommitTime: base, Repository: "api"},
+		{Hash: "w1", CommitTime: base, Repository: "web"},
+	}
+
+	history := git.NewMultiHistory(map[string]git.History{"web": web, "api": api})
+	commits, err := history.GetCommits("", base, base.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, expected, commits)
+
+	revisionTime, err := history.RevisionTime("v1")
+	require.NoError(t, err)
+	assert.Equal(t, base, revisionTime)
+	revisionTime, err = history.RevisionTime("v2")
+	require.NoError(t, err)
+	assert.Equal(t, base.Add(3*time.Hour), revisionTime)
+	_, err = history.RevisionTime("v3")
+	assert.Error(t, err)
+
+	details, err := history.CommitDetails(commits[3])
+	require.NoError(t, err)
+	assert.Equal(t, git.CommitDetails{Body: "The body of w1."}, details)
+	_, err = history.CommitDetails(commits[2])
+	assert.Error(t, err)
+	_, err = history.CommitDetails(git.Commit{Hash: "w1", Repository: "mobile"})
+	assert.EqualError(t, err, `unknown repository "mobile"`)
+}
@@ -0,0 +1,424 @@
+package git
+
+import (
+	"cmp"
+	"container/heap"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NativeHistory reads history straight from a repository's object store without running git.
+type NativeHistory struct {
+	repo *Repository
+}
+
+var _ History = (*NativeHistory)(nil)
+
+func NewNativeHistory(repo *Repository) *NativeHistory {
+	return &NativeHistory{repo: repo}
+}
+
+func (h *NativeHistory) GetCommits(ref string, start, end time.Time) ([]Commit, error) {
+	tip, err := h.resolveCommit(cmp.Or(ref, "HEAD"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Like "git log", authors are mapped through the repository's .mailmap.
+	mailmap, err := h.repo.Mailmap()
+	if err != nil {
+		return nil, fmt.Errorf("error when reading .mailmap: %w", err)
+	}
+
+	var commits []Commit
+	err = h.walk(tip, start, func(c *commitObject) error {
+		if c.committer.When.After(end) {
+			return nil
+		}
+
+		files, err := h.changedFiles(c)
+		if err != nil {
+			return err
+		}
+
+		authorName, authorEmail := mailmap.Resolve(c.author.Name, c.author.Email)
+		var coAuthors []Identity
+		for _, coAuthor := range parseCoAuthors(c.message) {
+			coAuthor.Name, coAuthor.Email = mailmap.Resolve(coAuthor.Name, coAuthor.Email)
+			coAuthors = append(coAuthors, coAuthor)
+		}
+
+		commits = append(commits, Commit{
+			Hash:        c.hash.String(),
+			AuthorName:  authorName,
+			AuthorEmail: authorEmail,
+			AuthorTime:  c.author.When,
+			CommitTime:  c.committer.When,
+			SubjectLine: subjectLine(c.message),
+			CoAuthors:   coAuthors,
+			Files:       files,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error when reading git history: %w", err)
+	}
+
+	return commits, nil
+}
+
+func (h *NativeHistory) RevisionTime(rev string) (time.Time, error) {
+	hash, err := h.resolveCommit(rev)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	c, err := h.readCommit(hash)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return c.committer.When, nil
+}
+
+// resolveCommit resolves a revision to the hash of the commit it points to.
+func (h *NativeHistory) resolveCommit(rev string) (Hash, error) {
+	hash, err := h.repo.ResolveRevision(rev)
+	if err != nil {
+		return Hash{}, err
+	}
+
+	// Peel annotated tags until we reach the commit. Tags of tags are rare, but allowed.
+	const maxDepth = 10
+	for range maxDepth {
+		objType, data, err := h.repo.objects.read(hash)
+		if err != nil {
+			return Hash{}, fmt.Errorf("error when reading revision %q: %w", rev, err)
+		}
+
+		switch objType {
+		case objectCommit:
+			return hash, nil
+		case objectTag:
+			hash, err = parseTagTarget(hash, data)
+			if err != nil {
+				return Hash{}, err
+			}
+		default:
+			return Hash{}, fmt.Errorf("revision %q isn't a commit", rev)
+		}
+	}
+
+	return Hash{}, fmt.Errorf("too many levels of tags resolving %q", rev)
+}
+
+// CommitDetails reads the message body and patch of the commit.
+func (h *NativeHistory) CommitDetails(commit Commit) (CommitDetails, error) {
+	hash, err := ParseHash(commit.Hash)
+	if err != nil {
+		return CommitDetails{}, err
+	}
+	c, err := h.readCommit(hash)
+	if err != nil {
+		return CommitDetails{}, fmt.Errorf("error when reading commit %s: %w", commit.Hash, err)
+	}
+
+	changes, err := h.commitChanges(c)
+	if err != nil {
+		return CommitDetails{}, err
+	}
+
+	var patch strings.Builder
+	for _, change := range changes {
+		oldData, err := h.readFileContents(change.old)
+		if err != nil {
+			return CommitDetails{}, err
+		}
+		newData, err := h.readFileContents(change.new)
+		if err != nil {
+			return CommitDetails{}, err
+		}
+		if isBinary(oldData) || isBinary(newData) {
+			continue
+		}
+
+		writePatch(&patch, change, oldData, newData)
+	}
+
+	return CommitDetails{Body: messageBody(c.message), Patch: patch.String()}, nil
+}
+
+// commitChanges lists the files changed by a commit relative to its parent.
+// Like "git log", nothing is listed for merge commits.
+func (h *NativeHistory) commitChanges(c *commitObject) ([]treeChange, error) {
+	var parentTree Hash
+	switch len(c.parents) {
+	case 0:
+		// Root commits are compared against the empty tree.
+	case 1:
+		parent, err := h.readCommit(c.parents[0])
+		if err != nil {
+			return nil, err
+		}
+		parentTree = parent.tree
+	default:
+		return nil, nil
+	}
+
+	var changes []treeChange
+	err := h.diffTrees(parentTree, c.tree, "", &changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// changedFiles lists the files changed by a commit and how many lines were changed in each.
+func (h *NativeHistory) changedFiles(c *commitObject) ([]FileChange, error) {
+	if len(c.parents) > 1 {
+		return nil, nil
+	}
+
+	changes, err := h.commitChanges(c)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		oldData, err := h.readFileContents(change.old)
+		if err != nil {
+			return nil, err
+		}
+		newData, err := h.readFileContents(change.new)
+		if err != nil {
+			return nil, err
+		}
+
+		fileChange := FileChange{Path: change.path}
+		if isBinary(oldData) || isBinary(newData) {
+			fileChange.Binary = true
+		} else {
+			fileChange.Additions, fileChange.Deletions = countLineChanges(oldData, newData)
+		}
+		result = append(result, fileChange)
+	}
+
+	return result, nil
+}
+
+// readFileContents reads the contents of a non-tree entry. A nil entry has no contents.
+func (h *NativeHistory) readFileContents(entry *treeEntry) ([]byte, error) {
+	switch {
+	case entry == nil:
+		return nil, nil
+	case entry.mode == modeGitlink:
+		// Submodules are shown by git as the commit they point at.
+		return []byte("Subproject commit " + entry.hash.String() + "\n"), nil
+	}
+
+	return h.repo.objects.readTyped(entry.hash, objectBlob)
+}
+
+// treeChange is a file that differs between two trees. Either old or new is nil if the file was added or deleted.
+type treeChange struct {
+	path     string
+	old, new *treeEntry
+}
+
+// diffTrees appends all files that differ between two trees. A zero hash is treated as an empty tree.
+// Changes are listed in the same order as git.
+func (h *NativeHistory) diffTrees(oldHash, newHash Hash, prefix string, result *[]treeChange) error {
+	if oldHash == newHash {
+		return nil
+	}
+
+	oldEntries, err := h.readTree(oldHash)
+	if err != nil {
+		return err
+	}
+	newEntries, err := h.readTree(newHash)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string][2]*treeEntry{}
+	for i := range oldEntries {
+		pair := byName[oldEntries[i].name]
+		pair[0] = &oldEntries[i]
+		byName[oldEntries[i].name] = pair
+	}
+	for i := range newEntries {
+		pair := byName[newEntries[i].name]
+		pair[1] = &newEntries[i]
+		byName[newEntries[i].name] = pair
+	}
+
+	// Git sorts trees as if their names ended with a slash.
+	sortKey := func(name string) string {
+		pair := byName[name]
+		if (pair[0] != nil && pair[0].isTree()) || (pair[1] != nil && pair[1].isTree()) {
+			return name + "/"
+		}
+		return name
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return strings.Compare(sortKey(a), sortKey(b))
+	})
+
+	for _, name := range names {
+		oldEntry, newEntry := byName[name][0], byName[name][1]
+		path := prefix + name
+
+		var oldSubtree, newSubtree Hash
+		var oldFile, newFile *treeEntry
+		if oldEntry != nil {
+			if oldEntry.isTree() {
+				oldSubtree = oldEntry.hash
+			} else {
+				oldFile = oldEntry
+			}
+		}
+		if newEntry != nil {
+			if newEntry.isTree() {
+				newSubtree = newEntry.hash
+			} else {
+				newFile = newEntry
+			}
+		}
+
+		if oldFile != nil || newFile != nil {
+			unchanged := oldFile != nil && newFile != nil && *oldFile == *newFile
+			if !unchanged {
+				*result = append(*result, treeChange{path: path, old: oldFile, new: newFile})
+			}
+		}
+
+		err := h.diffTrees(oldSubtree, newSubtree, path+"/", result)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *NativeHistory) readTree(hash Hash) ([]treeEntry, error) {
+	if hash == (Hash{}) {
+		return nil, nil
+	}
+
+	data, err := h.repo.objects.readTyped(hash, objectTree)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTree(hash, data)
+}
+
+func (h *NativeHistory) readCommit(hash Hash) (*commitObject, error) {
+	data, err := h.repo.objects.readTyped(hash, objectCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCommit(hash, data)
+}
+
+// walk visits the commits reachable from tip newest first, the same order as "git log".
+// Like "git log --since", the walk doesn't continue past commits that are older than since.
+func (h *NativeHistory) walk(tip Hash, since time.Time, visit func(*commitObject) error) error {
+	// The parents of shallow commits weren't cloned, so the walk has to stop at them.
+	shallow, err := h.repo.shallowCommits()
+	if err != nil {
+		return err
+	}
+
+	seen := map[Hash]struct{}{}
+	queue := &commitQueue{}
+	push := func(hash Hash) error {
+		if _, ok := seen[hash]; ok {
+			return nil
+		}
+		seen[hash] = struct{}{}
+
+		c, err := h.readCommit(hash)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, queuedCommit{commit: c, order: queue.pushed})
+		queue.pushed++
+		return nil
+	}
+
+	err = push(tip)
+	if err != nil {
+		return err
+	}
+
+	for queue.Len() > 0 {
+		c := heap.Pop(queue).(queuedCommit).commit
+		if !since.IsZero() && c.committer.When.Before(since) {
+			continue
+		}
+
+		if _, ok := shallow[c.hash]; ok {
+			// Like git, treat shallow commits as root commits.
+			c.parents = nil
+		}
+
+		err := visit(c)
+		if err != nil {
+			return err
+		}
+
+		for _, parent := range c.parents {
+			err := push(parent)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type queuedCommit struct {
+	commit *commitObject
+	// order breaks ties between commits with the same date by the order they were found.
+	order int
+}
+
+// commitQueue is a priority queue of commits ordered by newest committer date.
+type commitQueue struct {
+	items  []queuedCommit
+	pushed int
+}
+
+func (q *commitQueue) Len() int { return len(q.items) }
+
+func (q *commitQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if !a.commit.committer.When.Equal(b.commit.committer.When) {
+		return a.commit.committer.When.After(b.commit.committer.When)
+	}
+
+	return a.order < b.order
+}
+
+func (q *commitQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *commitQueue) Push(x any) { q.items = append(q.items, x.(queuedCommit)) }
+
+func (q *commitQueue) Pop() any {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
@@ -0,0 +1,165 @@
+package git_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/command"
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitMessage(i int) string {
+	message := fmt.Sprintf("commit number %d\n\nSome body text.", i)
+	switch i % 4 {
+	case 1:
+		message += "\n\nCo-authored-by: Bob <bob@example.com>\nCo-authored-by: Carol <carol@example.com>"
+	case 2:
+		message += "\n\nSigned-off-by: Alice <alice@example.com>"
+	}
+
+	return message
+}
+
+func TestNativeHistory_MatchesCLI(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	dir := repo.Dir
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo.WriteFile(".mailmap", "Caroline <caroline@example.com> <carol@example.com>\n")
+
+	authors := []string{"Alice <alice@example.com>", "Bob <bob@example.com>", "Carol <carol@example.com>"}
+	for i := range 30 {
+		date := base.Add(time.Duration(i) * 24 * time.Hour)
+		path := filepath.Join(dir, fmt.Sprintf("dir%d", i%3), fmt.Sprintf("file%d.txt", i%5))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("line\nchange %d\n", i)), 0o644))
+
+		if i == 10 {
+			repo.Git(date, "checkout", "--quiet", "-b", "feature")
+		}
+		if i == 15 {
+			repo.Git(date, "checkout", "--quiet", "main")
+		}
+
+		switch i {
+		case 7:
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "image.bin"), []byte{0, 1, 2, 3}, 0o644))
+		case 16:
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "no newline.txt"), []byte("a\nb"), 0o644))
+		case 18:
+			require.NoError(t, os.Rename(filepath.Join(dir, "no newline.txt"), filepath.Join(dir, "renamed.txt")))
+		case 22:
+			require.NoError(t, os.Chmod(filepath.Join(dir, "renamed.txt"), 0o755))
+		}
+
+		repo.Commit(gittest.Commit{Author: authors[i%len(authors)], Date: date, Message: commitMessage(i)})
+
+		if i == 12 {
+			repo.Git(date, "tag", "lightweight")
+			repo.Git(date, "tag", "--annotate", "annotated", "-m", "an annotated tag")
+		}
+		if i == 20 {
+			repo.Git(date, "merge", "--quiet", "--no-ff", "feature", "-m", "merge the feature branch")
+		}
+	}
+
+	cli := git.CLIHistory{Runner: command.ExecRunner{Dir: dir}}
+	found, err := git.FindRepository(filepath.Join(dir, "dir0"))
+	require.NoError(t, err)
+	native := git.NewNativeHistory(found)
+
+	assertMatches := func(t *testing.T) {
+		start, end := base.Add(5*24*time.Hour), base.Add(25*24*time.Hour)
+		expected, err := cli.GetCommits("", start, end)
+		require.NoError(t, err)
+		actual, err := native.GetCommits("", start, end)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+
+		// Co-authors should have the mailmap applied.
+		var coAuthors []git.Identity
+		for _, commit := range actual {
+			coAuthors = append(coAuthors, commit.CoAuthors...)
+		}
+		assert.Contains(t, coAuthors, git.Identity{Name: "Caroline", Email: "caroline@example.com"})
+
+		for _, commit := range expected {
+			expectedDetails, err := cli.CommitDetails(commit)
+			require.NoError(t, err)
+			actualDetails, err := native.CommitDetails(commit)
+			require.NoError(t, err)
+			assert.Equal(t, expectedDetails, actualDetails, commit.SubjectLine)
+		}
+
+		revs := []string{"HEAD", "main", "feature", "refs/heads/feature", "lightweight", "annotated", actual[3].Hash, actual[3].Hash[:7], actual[4].Hash[:12]}
+		for _, rev := range revs {
+			expected, err := cli.RevisionTime(rev)
+			require.NoError(t, err)
+			actual, err := native.RevisionTime(rev)
+			require.NoError(t, err, rev)
+			assert.Equal(t, expected, actual, rev)
+		}
+		_, err = native.RevisionTime("missing")
+		assert.Error(t, err)
+		// Like git, abbreviated hashes must have at least 4 digits.
+		_, err = native.RevisionTime(actual[3].Hash[:3])
+		assert.Error(t, err)
+
+		for _, ref := range []string{"feature", "annotated"} {
+			expected, err := cli.GetCommits(ref, start, end)
+			require.NoError(t, err)
+			actual, err := native.GetCommits(ref, start, end)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual, ref)
+		}
+		_, err = native.GetCommits("missing", start, end)
+		assert.Error(t, err)
+	}
+
+	t.Run("loose objects", assertMatches)
+
+	repo.Git(base, "gc", "--quiet", "--aggressive")
+	found, err = git.FindRepository(dir)
+	require.NoError(t, err)
+	native = git.NewNativeHistory(found)
+
+	t.Run("packed objects", assertMatches)
+
+	// Closing the repository closes its packfiles.
+	require.NoError(t, found.Close())
+	_, err = native.RevisionTime("HEAD")
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestNativeHistory_RescansPacks(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo.WriteFile("file.txt", "first\n")
+	repo.Commit(gittest.Commit{Author: "Alice <alice@example.com>", Date: base, Message: "first commit"})
+	repo.Git(base, "gc", "--quiet")
+
+	found, err := git.FindRepository(repo.Dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { found.Close() })
+	native := git.NewNativeHistory(found)
+
+	// Repacking after the repository was opened replaces the pack it opened with a new one.
+	later := base.Add(24 * time.Hour)
+	repo.WriteFile("file.txt", "second\n")
+	repo.Commit(gittest.Commit{Author: "Bob <bob@example.com>", Date: later, Message: "second commit"})
+	repo.Git(later, "gc", "--quiet")
+
+	commits, err := native.GetCommits("", base, later)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "second commit", commits[0].SubjectLine)
+
+	details, err := native.CommitDetails(commits[0])
+	require.NoError(t, err)
+	assert.Contains(t, details.Patch, "+second")
+}
@@ -0,0 +1,372 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Hash is the SHA-1 name of a git object.
+type Hash [20]byte
+
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	if len(s) != hex.EncodedLen(len(h)) {
+		return Hash{}, fmt.Errorf("invalid object hash %q", s)
+	}
+
+	_, err := hex.Decode(h[:], []byte(s))
+	if err != nil {
+		return Hash{}, fmt.Errorf("invalid object hash %q: %w", s, err)
+	}
+
+	return h, nil
+}
+
+// minAbbreviatedHash is the length of the shortest abbreviated hash that's looked up, the same as git's.
+const minAbbreviatedHash = 4
+
+// isAbbreviatedHash returns whether s could be an abbreviated object hash.
+func isAbbreviatedHash(s string) bool {
+	if len(s) < minAbbreviatedHash || len(s) >= hex.EncodedLen(len(Hash{})) {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+type objectType int
+
+const (
+	objectCommit   objectType = 1
+	objectTree     objectType = 2
+	objectBlob     objectType = 3
+	objectTag      objectType = 4
+	objectOfsDelta objectType = 6
+	objectRefDelta objectType = 7
+)
+
+func parseObjectType(s string) (objectType, error) {
+	switch s {
+	case "commit":
+		return objectCommit, nil
+	case "tree":
+		return objectTree, nil
+	case "blob":
+		return objectBlob, nil
+	case "tag":
+		return objectTag, nil
+	}
+
+	return 0, fmt.Errorf("unknown object type %q", s)
+}
+
+var errObjectNotFound = errors.New("object not found")
+
+// objectStore reads objects from the loose object directories and packfiles of a repository.
+type objectStore struct {
+	// dirs are the object directories to search. The first is the repository's own, the rest are alternates.
+	dirs []string
+
+	// mu guards the packs, which are rescanned when git adds new ones, e.g. after a fetch or gc. packPaths are the
+	// paths of the packs that have been opened, without the extension.
+	mu        sync.RWMutex
+	packs     []*packfile
+	packPaths map[string]bool
+	closed    bool
+}
+
+func openObjectStore(dir string) (*objectStore, error) {
+	store := &objectStore{packPaths: map[string]bool{}}
+	err := store.addDir(dir, 0)
+	if err != nil {
+		store.close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *objectStore) addDir(dir string, depth int) error {
+	// Git itself limits the alternates chain to 5 levels.
+	if depth > 5 {
+		return errors.New("too many levels of alternate object directories")
+	}
+	s.dirs = append(s.dirs, dir)
+
+	_, err := s.openPacks(dir)
+	if err != nil {
+		return err
+	}
+
+	alternates, err := os.ReadFile(filepath.Join(dir, "info", "alternates"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, alternate := range strings.Split(string(alternates), "\n") {
+		alternate = strings.TrimSpace(alternate)
+		if alternate == "" || alternate[0] == '#' {
+			continue
+		}
+		if !filepath.IsAbs(alternate) {
+			alternate = filepath.Join(dir, alternate)
+		}
+
+		err := s.addDir(alternate, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openPacks opens the packs in the object directory that haven't been opened yet and returns whether there were any. The
+// caller must hold mu for writing, or be the only user of the store.
+func (s *objectStore) openPacks(dir string) (bool, error) {
+	indexPaths, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+	if err != nil {
+		return false, err
+	}
+
+	opened := false
+	for _, indexPath := range indexPaths {
+		basePath := strings.TrimSuffix(indexPath, ".idx")
+		if s.packPaths[basePath] {
+			continue
+		}
+
+		pack, err := openPackfile(basePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// git removed the pack while we were looking, e.g. during a repack.
+				continue
+			}
+			return false, err
+		}
+		s.packs = append(s.packs, pack)
+		s.packPaths[basePath] = true
+		opened = true
+	}
+
+	return opened, nil
+}
+
+// rescanPacks opens any packs that git has added since the store was opened and returns whether there were any.
+func (s *objectStore) rescanPacks() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false, nil
+	}
+
+	opened := false
+	for _, dir := range s.dirs {
+		ok, err := s.openPacks(dir)
+		if err != nil {
+			return false, fmt.Errorf("error rescanning packs in %s: %w", dir, err)
+		}
+		opened = opened || ok
+	}
+
+	return opened, nil
+}
+
+func (s *objectStore) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+
+	var errs []error
+	for _, pack := range s.packs {
+		errs = append(errs, pack.close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// read returns the type and contents of the given object.
+func (s *objectStore) read(hash Hash) (objectType, []byte, error) {
+	objType, data, err := s.readOpened(hash)
+	if errors.Is(err, errObjectNotFound) {
+		// The object may have been fetched, or repacked from a loose object, since the packs were last scanned.
+		opened, scanErr := s.rescanPacks()
+		if scanErr != nil {
+			return 0, nil, scanErr
+		}
+		if opened {
+			return s.readOpened(hash)
+		}
+	}
+
+	return objType, data, err
+}
+
+// readOpened reads the given object from the loose objects and the packs that are already open.
+func (s *objectStore) readOpened(hash Hash) (objectType, []byte, error) {
+	s.mu.RLock()
+	packs := s.packs
+	s.mu.RUnlock()
+
+	for _, pack := range packs {
+		if offset, ok := pack.find(hash); ok {
+			return pack.readAt(offset, s)
+		}
+	}
+
+	for _, dir := range s.dirs {
+		objType, data, err := readLooseObject(dir, hash)
+		if errors.Is(err, errObjectNotFound) {
+			continue
+		}
+		return objType, data, err
+	}
+
+	return 0, nil, fmt.Errorf("%w: %s", errObjectNotFound, hash)
+}
+
+// resolvePrefix returns the only object whose lower-case hex hash starts with the prefix.
+func (s *objectStore) resolvePrefix(prefix string) (Hash, error) {
+	matches, err := s.matchPrefix(prefix)
+	if err != nil {
+		return Hash{}, err
+	}
+	if len(matches) == 0 {
+		// The object may have been fetched since the packs were last scanned.
+		opened, err := s.rescanPacks()
+		if err != nil {
+			return Hash{}, err
+		}
+		if opened {
+			matches, err = s.matchPrefix(prefix)
+			if err != nil {
+				return Hash{}, err
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Hash{}, fmt.Errorf("%w: %s", errObjectNotFound, prefix)
+	case 1:
+		for hash := range matches {
+			return hash, nil
+		}
+	}
+
+	return Hash{}, fmt.Errorf("abbreviated hash %s is ambiguous", prefix)
+}
+
+// matchPrefix returns the objects in the open packs and the loose object directories whose lower-case hex hash starts
+// with the prefix.
+func (s *objectStore) matchPrefix(prefix string) (map[Hash]struct{}, error) {
+	s.mu.RLock()
+	packs := s.packs
+	s.mu.RUnlock()
+
+	matches := map[Hash]struct{}{}
+	for _, pack := range packs {
+		for _, hash := range pack.withPrefix(prefix) {
+			matches[hash] = struct{}{}
+		}
+	}
+
+	// Loose objects are stored in a directory named by the first two digits of their hash.
+	for _, dir := range s.dirs {
+		entries, err := os.ReadDir(filepath.Join(dir, prefix[:2]))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), prefix[2:]) {
+				continue
+			}
+			// Skip anything that isn't an object, like temporary files.
+			if hash, err := ParseHash(prefix[:2] + entry.Name()); err == nil {
+				matches[hash] = struct{}{}
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// readTyped reads the given object and verifies that it has the expected type.
+func (s *objectStore) readTyped(hash Hash, expected objectType) ([]byte, error) {
+	objType, data, err := s.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	if objType != expected {
+		return nil, fmt.Errorf("object %s has type %d, expected %d", hash, objType, expected)
+	}
+
+	return data, nil
+}
+
+func readLooseObject(dir string, hash Hash) (objectType, []byte, error) {
+	name := hash.String()
+	f, err := os.Open(filepath.Join(dir, name[:2], name[2:]))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil, errObjectNotFound
+		}
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	z, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading object %s: %w", hash, err)
+	}
+	defer z.Close()
+
+	contents, err := io.ReadAll(z)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading object %s: %w", hash, err)
+	}
+
+	// Loose objects start with a "<type> <size>\x00" header.
+	header, data, ok := bytes.Cut(contents, []byte{0})
+	if !ok {
+		return 0, nil, fmt.Errorf("object %s has an invalid header", hash)
+	}
+	typeName, sizeString, ok := strings.Cut(string(header), " ")
+	if !ok {
+		return 0, nil, fmt.Errorf("object %s has an invalid header", hash)
+	}
+	objType, err := parseObjectType(typeName)
+	if err != nil {
+		return 0, nil, err
+	}
+	size, err := strconv.Atoi(sizeString)
+	if err != nil || size != len(data) {
+		return 0, nil, fmt.Errorf("object %s has an invalid size", hash)
+	}
+
+	return objType, data, nil
+}
@@ -0,0 +1,377 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// packfile reads objects from a pack and its version 2 index.
+type packfile struct {
+	file *os.File
+
+	// hashes are the sorted object names in the pack and offsets are the offset of the object with the same index.
+	hashes  []Hash
+	offsets []int64
+
+	// cache holds recently resolved objects so that long delta chains don't need to be re-applied each time.
+	mu        sync.Mutex
+	cache     map[int64]cachedObject
+	cacheSize int
+}
+
+type cachedObject struct {
+	objType objectType
+	data    []byte
+}
+
+// maxPackCacheSize is the total number of bytes of resolved objects to keep cached per pack.
+const maxPackCacheSize = 64 << 20
+
+func openPackfile(basePath string) (*packfile, error) {
+	hashes, offsets, err := readPackIndex(basePath + ".idx")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(basePath + ".pack")
+	if err != nil {
+		return nil, err
+	}
+
+	var header [12]byte
+	_, err = f.ReadAt(header[:], 0)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error reading pack header %s: %w", basePath, err)
+	}
+	if string(header[:4]) != "PACK" {
+		f.Close()
+		return nil, fmt.Errorf("%s.pack is not a packfile", basePath)
+	}
+	if version := binary.BigEndian.Uint32(header[4:8]); version != 2 && version != 3 {
+		f.Close()
+		return nil, fmt.Errorf("unsupported pack version %d", version)
+	}
+
+	return &packfile{
+		file:    f,
+		hashes:  hashes,
+		offsets: offsets,
+		cache:   map[int64]cachedObject{},
+	}, nil
+}
+
+// close closes the pack and drops its cache, so its objects can't be read afterwards.
+func (p *packfile) close() error {
+	p.mu.Lock()
+	p.cache = nil
+	p.cacheSize = 0
+	p.mu.Unlock()
+
+	return p.file.Close()
+}
+
+func readPackIndex(path string) ([]Hash, []int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invalid := fmt.Errorf("invalid pack index %s", path)
+
+	const headerSize = 8
+	const fanoutSize = 256 * 4
+	if len(data) < headerSize+fanoutSize || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) {
+		return nil, nil, invalid
+	}
+	if version := binary.BigEndian.Uint32(data[4:8]); version != 2 {
+		return nil, nil, fmt.Errorf("unsupported pack index version %d", version)
+	}
+
+	// The last fanout entry is the total number of objects.
+	count := int(binary.BigEndian.Uint32(data[headerSize+fanoutSize-4:]))
+	hashStart := headerSize + fanoutSize
+	crcStart := hashStart + count*20
+	offsetStart := crcStart + count*4
+	largeOffsetStart := offsetStart + count*4
+	if len(data) < largeOffsetStart {
+		return nil, nil, invalid
+	}
+
+	hashes := make([]Hash, count)
+	offsets := make([]int64, count)
+	for i := range count {
+		copy(hashes[i][:], data[hashStart+i*20:])
+
+		offset := binary.BigEndian.Uint32(data[offsetStart+i*4:])
+		if offset&0x80000000 == 0 {
+			offsets[i] = int64(offset)
+			continue
+		}
+
+		// Offsets past 2GiB are stored in a separate table of 8-byte entries.
+		largeIndex := largeOffsetStart + int(offset&0x7fffffff)*8
+		if len(data) < largeIndex+8 {
+			return nil, nil, invalid
+		}
+		offsets[i] = int64(binary.BigEndian.Uint64(data[largeIndex:]))
+	}
+
+	return hashes, offsets, nil
+}
+
+func (p *packfile) find(hash Hash) (int64, bool) {
+	i := sort.Search(len(p.hashes), func(i int) bool {
+		return bytes.Compare(p.hashes[i][:], hash[:]) >= 0
+	})
+	if i < len(p.hashes) && p.hashes[i] == hash {
+		return p.offsets[i], true
+	}
+
+	return 0, false
+}
+
+// withPrefix returns the hashes of the objects in the pack whose lower-case hex hash starts with the prefix.
+func (p *packfile) withPrefix(prefix string) []Hash {
+	// Padding an odd number of digits with a zero gives the smallest hash with the prefix.
+	var smallest Hash
+	_, err := hex.Decode(smallest[:], []byte(prefix+strings.Repeat("0", len(prefix)%2)))
+	if err != nil {
+		return nil
+	}
+
+	var result []Hash
+	i := sort.Search(len(p.hashes), func(i int) bool {
+		return bytes.Compare(p.hashes[i][:], smallest[:]) >= 0
+	})
+	for ; i < len(p.hashes) && strings.HasPrefix(p.hashes[i].String(), prefix); i++ {
+		result = append(result, p.hashes[i])
+	}
+
+	return result
+}
+
+// readAt reads and fully resolves the object at the given offset.
+// The store is used to look up the bases of ref deltas, which can live outside this pack.
+func (p *packfile) readAt(offset int64, store *objectStore) (objectType, []byte, error) {
+	p.mu.Lock()
+	cached, ok := p.cache[offset]
+	p.mu.Unlock()
+	if ok {
+		return cached.objType, cached.data, nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(p.file, offset, 1<<62))
+	objType, size, err := readPackEntryHeader(r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading pack entry at %d: %w", offset, err)
+	}
+
+	var baseType objectType
+	var base []byte
+	switch objType {
+	case objectCommit, objectTree, objectBlob, objectTag:
+		// Not a delta, so the data can be used as is.
+	case objectOfsDelta:
+		distance, err := readOffsetDeltaDistance(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err = p.readAt(offset-distance, store)
+		if err != nil {
+			return 0, nil, err
+		}
+	case objectRefDelta:
+		var baseHash Hash
+		_, err := io.ReadFull(r, baseHash[:])
+		if err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err = store.read(baseHash)
+		if err != nil {
+			return 0, nil, err
+		}
+	default:
+		return 0, nil, fmt.Errorf("unknown pack object type %d", objType)
+	}
+
+	data, err := inflate(r, size)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading pack entry at %d: %w", offset, err)
+	}
+
+	if base != nil {
+		objType = baseType
+		data, err = applyDelta(base, data)
+		if err != nil {
+			return 0, nil, fmt.Errorf("error applying delta at %d: %w", offset, err)
+		}
+	}
+
+	p.addToCache(offset, cachedObject{objType: objType, data: data})
+	return objType, data, nil
+}
+
+func (p *packfile) addToCache(offset int64, object cachedObject) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cache == nil {
+		// The pack has been closed.
+		return
+	}
+	if len(object.data) > maxPackCacheSize/4 {
+		// Don't let one huge object evict everything else.
+		return
+	}
+	if p.cacheSize+len(object.data) > maxPackCacheSize {
+		// Simply start over rather than tracking usage. Delta chains are usually local so this works well enough.
+		p.cache = map[int64]cachedObject{}
+		p.cacheSize = 0
+	}
+
+	p.cache[offset] = object
+	p.cacheSize += len(object.data)
+}
+
+// readPackEntryHeader reads the variable-length type and inflated size that starts every pack entry.
+func readPackEntryHeader(r io.ByteReader) (objectType, int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	objType := objectType((b >> 4) & 0x7)
+	size := int(b & 0x0f)
+	shift := 4
+	for b&0x80 != 0 {
+		b, err = r.ReadByte()
+		if err != nil {
+			return 0, 0, err
+		}
+		size |= int(b&0x7f) << shift
+		shift += 7
+	}
+
+	return objType, size, nil
+}
+
+// readOffsetDeltaDistance reads how far before the current entry an offset delta's base is.
+func readOffsetDeltaDistance(r io.ByteReader) (int64, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	distance := int64(b & 0x7f)
+	for b&0x80 != 0 {
+		b, err = r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		distance = ((distance + 1) << 7) | int64(b&0x7f)
+	}
+
+	return distance, nil
+}
+
+func inflate(r io.Reader, size int) ([]byte, error) {
+	z, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	data := make([]byte, size)
+	_, err = io.ReadFull(z, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// applyDelta builds an object from its base and a git delta.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	r := bytes.NewReader(delta)
+
+	baseSize, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if int(baseSize) != len(base) {
+		return nil, errors.New("delta base size mismatch")
+	}
+	resultSize, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, resultSize)
+	for {
+		cmd, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case cmd&0x80 != 0:
+			// Copy a range out of the base. The low bits say which offset and size bytes are present.
+			var offset, size uint32
+			for i := range 4 {
+				if cmd&(1<<i) != 0 {
+					b, err := r.ReadByte()
+					if err != nil {
+						return nil, err
+					}
+					offset |= uint32(b) << (8 * i)
+				}
+			}
+			for i := range 3 {
+				if cmd&(0x10<<i) != 0 {
+					b, err := r.ReadByte()
+					if err != nil {
+						return nil, err
+					}
+					size |= uint32(b) << (8 * i)
+				}
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if uint64(offset)+uint64(size) > uint64(len(base)) {
+				return nil, errors.New("delta copy out of range")
+			}
+			result = append(result, base[offset:offset+size]...)
+		case cmd != 0:
+			// Insert the next cmd bytes of the delta.
+			start := len(result)
+			result = append(result, make([]byte, cmd)...)
+			_, err := io.ReadFull(r, result[start:])
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("invalid delta opcode")
+		}
+	}
+
+	if uint64(len(result)) != resultSize {
+		return nil, errors.New("delta result size mismatch")
+	}
+
+	return result, nil
+}
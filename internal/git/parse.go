@@ -0,0 +1,199 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature is the identity and timestamp recorded in a commit's author or committer header.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// commitObject is a parsed commit object.
+type commitObject struct {
+	hash      Hash
+	tree      Hash
+	parents   []Hash
+	author    Signature
+	committer Signature
+	message   string
+}
+
+func parseCommit(hash Hash, data []byte) (*commitObject, error) {
+	result := &commitObject{hash: hash}
+
+	headers, message, _ := bytes.Cut(data, []byte("\n\n"))
+	result.message = string(message)
+
+	for _, line := range strings.Split(string(headers), "\n") {
+		// Continuation lines belong to multi-line headers such as gpgsig, none of which we care about.
+		if line == "" || line[0] == ' ' {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		var err error
+		switch key {
+		case "tree":
+			result.tree, err = ParseHash(value)
+		case "parent":
+			var parent Hash
+			parent, err = ParseHash(value)
+			result.parents = append(result.parents, parent)
+		case "author":
+			result.author, err = parseSignature(value)
+		case "committer":
+			result.committer, err = parseSignature(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing commit %s: %w", hash, err)
+		}
+	}
+
+	return result, nil
+}
+
+// parseTagTarget returns the object that an annotated tag points to.
+func parseTagTarget(hash Hash, data []byte) (Hash, error) {
+	headers, _, _ := bytes.Cut(data, []byte("\n\n"))
+	for _, line := range strings.Split(string(headers), "\n") {
+		if value, ok := strings.CutPrefix(line, "object "); ok {
+			return ParseHash(value)
+		}
+	}
+
+	return Hash{}, fmt.Errorf("tag %s has no object", hash)
+}
+
+// parseSignature parses a "Name <email> <unix seconds> <+hhmm>" header value.
+func parseSignature(s string) (Signature, error) {
+	emailStart := strings.IndexByte(s, '<')
+	emailEnd := strings.LastIndexByte(s, '>')
+	if emailStart < 0 || emailEnd < emailStart {
+		return Signature{}, fmt.Errorf("invalid signature %q", s)
+	}
+
+	result := Signature{
+		Name:  strings.TrimSpace(s[:emailStart]),
+		Email: s[emailStart+1 : emailEnd],
+	}
+
+	fields := strings.Fields(s[emailEnd+1:])
+	if len(fields) == 0 {
+		return result, nil
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return Signature{}, fmt.Errorf("invalid signature timestamp %q", s)
+	}
+	location := time.UTC
+	if len(fields) > 1 {
+		location = parseTimezone(fields[1])
+	}
+	result.When = time.Unix(seconds, 0).In(location)
+
+	return result, nil
+}
+
+func parseTimezone(s string) *time.Location {
+	if len(s) != 5 || (s[0] != '+' && s[0] != '-') {
+		return time.UTC
+	}
+	hours, err1 := strconv.Atoi(s[1:3])
+	minutes, err2 := strconv.Atoi(s[3:5])
+	if err1 != nil || err2 != nil {
+		return time.UTC
+	}
+
+	offset := hours*60*60 + minutes*60
+	if s[0] == '-' {
+		offset = -offset
+	}
+
+	return time.FixedZone(s, offset)
+}
+
+// subjectLine returns the first paragraph of a commit message joined into a single line, the same as git's %s.
+func subjectLine(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			if len(lines) > 0 {
+				break
+			}
+			// Skip any blank lines before the subject.
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, " ")
+}
+
+type treeEntry struct {
+	name string
+	mode uint32
+	hash Hash
+}
+
+const (
+	modeTree    = 0o40000
+	modeGitlink = 0o160000
+)
+
+func (e treeEntry) isTree() bool {
+	return e.mode == modeTree
+}
+
+func parseTree(hash Hash, data []byte) ([]treeEntry, error) {
+	var result []treeEntry
+	for len(data) > 0 {
+		modeEnd := bytes.IndexByte(data, ' ')
+		if modeEnd < 0 {
+			return nil, fmt.Errorf("invalid tree %s", hash)
+		}
+		mode, err := strconv.ParseUint(string(data[:modeEnd]), 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tree %s: %w", hash, err)
+		}
+		data = data[modeEnd+1:]
+
+		nameEnd := bytes.IndexByte(data, 0)
+		if nameEnd < 0 || len(data) < nameEnd+1+len(Hash{}) {
+			return nil, fmt.Errorf("invalid tree %s", hash)
+		}
+		entry := treeEntry{
+			name: string(data[:nameEnd]),
+			mode: uint32(mode),
+		}
+		copy(entry.hash[:], data[nameEnd+1:])
+		data = data[nameEnd+1+len(Hash{}):]
+
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
+
+// messageBody returns the rest of a commit message after the subject, the same as git's %b.
+func messageBody(message string) string {
+	lines := strings.Split(message, "\n")
+	i := 0
+	// Skip the blank lines before the subject, then the subject itself.
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+		i++
+	}
+
+	return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+}
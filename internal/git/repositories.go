@@ -0,0 +1,110 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Repositories are the repositories that games are built from, sorted by name.
+type Repositories []*Repository
+
+// OpenRepositories opens the repositories containing each of the directories. A repository listed more than once is
+// only opened once, and every repository must have a different name.
+func OpenRepositories(dirs []string) (Repositories, error) {
+	var result Repositories
+	byPath := map[string]struct{}{}
+	byName := map[string]string{}
+	for _, dir := range dirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+
+		repo, err := FindRepository(dir)
+		if err != nil {
+			result.Close()
+			if errors.Is(err, ErrNotRepository) {
+				return nil, fmt.Errorf("%s isn't in a git repository", dir)
+			}
+			return nil, err
+		}
+
+		// The same repository may be listed more than once through different paths.
+		if _, ok := byPath[repo.Path()]; ok {
+			repo.Close()
+			continue
+		}
+		byPath[repo.Path()] = struct{}{}
+
+		// Commits are labelled by the name of their repository, so names must be unique.
+		if other, ok := byName[repo.Name()]; ok {
+			repo.Close()
+			result.Close()
+			return nil, fmt.Errorf("repositories %s and %s have the same name %q", other, repo.Path(), repo.Name())
+		}
+		byName[repo.Name()] = repo.Path()
+
+		result = append(result, repo)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("no repositories given")
+	}
+
+	// Sort so that nothing depends on the order the repositories were listed in.
+	slices.SortFunc(result, func(a, b *Repository) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return result, nil
+}
+
+// ID identifies the repositories by their paths, e.g. in saved games.
+func (r Repositories) ID() string {
+	var paths []string
+	for _, repo := range r {
+		paths = append(paths, repo.Path())
+	}
+
+	return strings.Join(paths, ",")
+}
+
+// Label names the repositories in shared results.
+func (r Repositories) Label() string {
+	var names []string
+	for _, repo := range r {
+		names = append(names, repo.Name())
+	}
+
+	return strings.Join(names, "+")
+}
+
+// History reads the combined history of the repositories with the given backend. The repositories are shared rather
+// than reopened, so they must stay open while the history is used.
+func (r Repositories) History(backend string) (History, error) {
+	if len(r) == 1 {
+		return NewHistory(r[0], backend)
+	}
+
+	histories := map[string]History{}
+	for _, repo := range r {
+		history, err := NewHistory(repo, backend)
+		if err != nil {
+			return nil, err
+		}
+		histories[repo.Name()] = history
+	}
+
+	return NewMultiHistory(histories), nil
+}
+
+// Close closes the repositories' packfiles.
+func (r Repositories) Close() error {
+	var errs []error
+	for _, repo := range r {
+		errs = append(errs, repo.Close())
+	}
+
+	return errors.Join(errs...)
+}
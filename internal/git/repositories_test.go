@@ -0,0 +1,51 @@
+package git_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNamedRepo creates a repository with one commit in a directory with the given name.
+func newNamedRepo(t *testing.T, name string) string {
+	repo := gittest.NewRepo(t)
+	repo.WriteFile("README", name+"\n")
+	repo.Commit(gittest.Commit{Author: "Alice <alice@example.com>", Date: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), Message: "Add a readme"})
+
+	dir := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.Rename(repo.Dir, dir))
+
+	return dir
+}
+
+func TestOpenRepositories(t *testing.T) {
+	web, api := newNamedRepo(t, "web"), newNamedRepo(t, "api")
+	require.NoError(t, os.Mkdir(filepath.Join(web, "src"), 0o755))
+
+	// The same repository through a subdirectory is only opened once, and blank entries are skipped.
+	repos, err := git.OpenRepositories([]string{web, " ", api, filepath.Join(web, "src")})
+	require.NoError(t, err)
+	t.Cleanup(func() { repos.Close() })
+
+	assert.Equal(t, "api+web", repos.Label())
+	assert.Equal(t, api+","+web, repos.ID())
+
+	history, err := repos.History("native")
+	require.NoError(t, err)
+	commits, err := history.GetCommits("", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Len(t, commits, 2)
+
+	_, err = git.OpenRepositories([]string{web, newNamedRepo(t, "web")})
+	assert.ErrorContains(t, err, `have the same name "web"`)
+	_, err = git.OpenRepositories([]string{t.TempDir()})
+	assert.ErrorContains(t, err, "isn't in a git repository")
+	_, err = git.OpenRepositories(nil)
+	assert.EqualError(t, err, "no repositories given")
+}
@@ -0,0 +1,260 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository is returned when a directory isn't inside a git repository.
+var ErrNotRepository = errors.New("not a git repository")
+
+// Repository provides read-only access to a git repository's refs and object store without needing the git binary.
+type Repository struct {
+	// gitDir is the repository's private directory. This holds HEAD and any per-worktree refs.
+	gitDir string
+	// commonDir is the directory holding the objects and refs shared between all worktrees.
+	// For repositories without linked worktrees this is the same as gitDir.
+	commonDir string
+	// workTree is the root of the checked out files. Empty for bare repositories.
+	workTree string
+
+	objects *objectStore
+}
+
+// FindRepository finds the repository containing dir, searching parent directories the same way git does.
+func FindRepository(dir string) (*Repository, error) {
+	if gitDir := os.Getenv("GIT_DIR"); gitDir != "" {
+		return openRepository(gitDir, os.Getenv("GIT_WORK_TREE"))
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		switch {
+		case err == nil && info.IsDir():
+			return openRepository(dotGit, dir)
+		case err == nil:
+			// A .git file points at the real git directory. This is used by worktrees and submodules.
+			gitDir, err := readGitFile(dotGit)
+			if err != nil {
+				return nil, err
+			}
+			return openRepository(gitDir, dir)
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+
+		// This may be a bare repository.
+		if isGitDir(dir) {
+			return openRepository(dir, "")
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotRepository
+		}
+		dir = parent
+	}
+}
+
+func openRepository(gitDir, workTree string) (*Repository, error) {
+	if !isGitDir(gitDir) {
+		return nil, fmt.Errorf("%s: %w", gitDir, ErrNotRepository)
+	}
+
+	commonDir := gitDir
+	if contents, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(contents))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	objects, err := openObjectStore(filepath.Join(commonDir, "objects"))
+	if err != nil {
+		return nil, fmt.Errorf("error opening object store: %w", err)
+	}
+
+	return &Repository{
+		gitDir:    gitDir,
+		commonDir: commonDir,
+		workTree:  workTree,
+		objects:   objects,
+	}, nil
+}
+
+func isGitDir(dir string) bool {
+	for _, name := range []string{"HEAD", "objects"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func readGitFile(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(contents)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("invalid gitfile format: %s", path)
+	}
+
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+
+	return gitDir, nil
+}
+
+// WorkTree returns the root directory of the repository's checked out files.
+// This is empty for bare repositories.
+func (r *Repository) WorkTree() string {
+	return r.workTree
+}
+
+// Path returns the root of the repository's checked out files, or the git directory for bare repositories.
+func (r *Repository) Path() string {
+	if r.workTree != "" {
+		return r.workTree
+	}
+
+	return r.gitDir
+}
+
+// Close closes the repository's packfiles. The repository can't be read from afterwards.
+func (r *Repository) Close() error {
+	return r.objects.close()
+}
+
+// Name returns a short name for the repository, which is the base name of its path.
+func (r *Repository) Name() string {
+	return strings.TrimSuffix(filepath.Base(r.Path()), ".git")
+}
+
+// Head resolves HEAD to a commit hash.
+func (r *Repository) Head() (Hash, error) {
+	return r.ResolveRef("HEAD")
+}
+
+// ResolveRef resolves a fully qualified ref name (e.g. "HEAD" or "refs/heads/main") to an object hash.
+func (r *Repository) ResolveRef(name string) (Hash, error) {
+	// Limit the depth of symbolic refs in case there's a cycle.
+	const maxDepth = 10
+	for range maxDepth {
+		target, err := r.readRef(name)
+		if err != nil {
+			return Hash{}, err
+		}
+
+		symbolic, ok := strings.CutPrefix(target, "ref:")
+		if !ok {
+			return ParseHash(target)
+		}
+		name = strings.TrimSpace(symbolic)
+	}
+
+	return Hash{}, fmt.Errorf("too many levels of symbolic refs resolving %s", name)
+}
+
+// revisionFormats are the formats of the ref names that a short ref name could refer to, in order of priority.
+var revisionFormats = []string{"%s", "refs/%s", "refs/tags/%s", "refs/heads/%s", "refs/remotes/%s", "refs/remotes/%s/HEAD"}
+
+// ResolveRevision resolves an object hash, a unique abbreviation of one, or a ref name to an object hash.
+// Short ref names (e.g. "main" or "v1.0") are looked up in the same order as gitrevisions(7). Like git, a ref name wins
+// over an abbreviated hash that it looks like.
+func (r *Repository) ResolveRevision(rev string) (Hash, error) {
+	if hash, err := ParseHash(rev); err == nil {
+		return hash, nil
+	}
+
+	for _, format := range revisionFormats {
+		hash, err := r.ResolveRef(fmt.Sprintf(format, rev))
+		if err == nil {
+			return hash, nil
+		}
+	}
+
+	if isAbbreviatedHash(rev) {
+		hash, err := r.objects.resolvePrefix(strings.ToLower(rev))
+		if err != nil {
+			return Hash{}, fmt.Errorf("unknown revision %q: %w", rev, err)
+		}
+		return hash, nil
+	}
+
+	return Hash{}, fmt.Errorf("unknown revision %q", rev)
+}
+
+// readRef returns the raw contents of a ref. This is either a hash or a symbolic "ref: <name>" target.
+func (r *Repository) readRef(name string) (string, error) {
+	// HEAD and other pseudo-refs are per-worktree. Everything under refs/ is shared.
+	dir := r.gitDir
+	if strings.HasPrefix(name, "refs/") {
+		dir = r.commonDir
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	// A directory with the same name as the ref isn't an error. The ref may still be packed.
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(contents)), nil
+	}
+
+	packed, err := r.packedRefs()
+	if err != nil {
+		return "", err
+	}
+	if hash, ok := packed[name]; ok {
+		return hash, nil
+	}
+
+	return "", fmt.Errorf("ref %s not found", name)
+}
+
+// packedRefs reads the packed-refs file into a map from ref name to hash.
+func (r *Repository) packedRefs() (map[string]string, error) {
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	result := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Skip comments, the header, and peeled tag lines.
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+
+		hash, name, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid packed-refs line %q", line)
+		}
+		result[name] = hash
+	}
+
+	return result, scanner.Err()
+}
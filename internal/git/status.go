@@ -0,0 +1,190 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RefStatus describes how likely a ref's history is to match everyone else's copy of the repository.
+type RefStatus struct {
+	// Ref is the full name of the ref that was checked, e.g. "refs/heads/main".
+	// This is empty if the revision wasn't a ref, such as a detached HEAD.
+	Ref string
+	// Upstream is the remote-tracking ref that Ref follows, e.g. "refs/remotes/origin/main".
+	// This is empty if there isn't one.
+	Upstream string
+	// Ahead is whether Ref has commits that Upstream doesn't.
+	Ahead bool
+	// Behind is whether Upstream has commits that Ref doesn't.
+	Behind bool
+	// NewestCommit is when the commit that Ref points to was committed.
+	NewestCommit time.Time
+	// Shallow is whether the repository is a shallow clone, which is missing older history.
+	Shallow bool
+}
+
+// Status checks how up to date a revision is without modifying the repository.
+func (h *NativeHistory) Status(rev string) (RefStatus, error) {
+	var result RefStatus
+
+	shallow, err := h.repo.shallowCommits()
+	if err != nil {
+		return RefStatus{}, err
+	}
+	result.Shallow = len(shallow) > 0
+
+	tip, err := h.resolveCommit(rev)
+	if err != nil {
+		return RefStatus{}, err
+	}
+	tipCommit, err := h.readCommit(tip)
+	if err != nil {
+		return RefStatus{}, err
+	}
+	result.NewestCommit = tipCommit.committer.When
+
+	result.Ref = h.repo.refName(rev)
+	result.Upstream, err = h.repo.upstream(result.Ref)
+	if err != nil {
+		return RefStatus{}, err
+	}
+	if result.Upstream == "" {
+		return result, nil
+	}
+
+	upstreamTip, err := h.resolveCommit(result.Upstream)
+	if err != nil {
+		// The upstream branch may not have been fetched yet.
+		result.Upstream = ""
+		return result, nil
+	}
+	if upstreamTip == tip {
+		return result, nil
+	}
+
+	result.Ahead, err = h.hasCommitsMissingFrom(tip, upstreamTip)
+	if err != nil {
+		return RefStatus{}, err
+	}
+	result.Behind, err = h.hasCommitsMissingFrom(upstreamTip, tip)
+	if err != nil {
+		return RefStatus{}, err
+	}
+
+	return result, nil
+}
+
+var errFoundCommit = errors.New("found commit")
+
+// hasCommitsMissingFrom returns whether any commit reachable from a isn't reachable from b.
+// This is the case unless a is an ancestor of b.
+func (h *NativeHistory) hasCommitsMissingFrom(a, b Hash) (bool, error) {
+	ancestor, err := h.readCommit(a)
+	if err != nil {
+		return false, err
+	}
+
+	// The ancestor can't be reached through commits older than it, so there's no need to walk them.
+	err = h.walk(b, ancestor.committer.When, func(c *commitObject) error {
+		if c.hash == a {
+			return errFoundCommit
+		}
+		return nil
+	})
+	if errors.Is(err, errFoundCommit) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// refName returns the full name of the ref that a revision refers to, following symbolic refs like HEAD.
+// An empty string is returned if the revision isn't a ref.
+func (r *Repository) refName(rev string) string {
+	for _, format := range revisionFormats {
+		name := fmt.Sprintf(format, rev)
+		target, err := r.readRef(name)
+		if err != nil {
+			continue
+		}
+
+		if symbolic, ok := strings.CutPrefix(target, "ref:"); ok {
+			return strings.TrimSpace(symbolic)
+		}
+		if name == "HEAD" {
+			// A detached HEAD.
+			return ""
+		}
+		return name
+	}
+
+	return ""
+}
+
+// upstream returns the remote-tracking ref that a local branch follows, or an empty string if it doesn't follow one.
+// This assumes the default fetch refspec of "refs/heads/*:refs/remotes/<remote>/*".
+func (r *Repository) upstream(ref string) (string, error) {
+	branch, ok := strings.CutPrefix(ref, "refs/heads/")
+	if !ok {
+		return "", nil
+	}
+
+	cfg, err := r.config()
+	if err != nil {
+		return "", fmt.Errorf("error when reading git config: %w", err)
+	}
+
+	remote := cfg.get("branch", branch, "remote")
+	merge := cfg.get("branch", branch, "merge")
+	if remote == "" || merge == "" {
+		return "", nil
+	}
+
+	mergeBranch, ok := strings.CutPrefix(merge, "refs/heads/")
+	if !ok {
+		return "", nil
+	}
+	if remote == "." {
+		// The branch follows another local branch.
+		return merge, nil
+	}
+
+	return "refs/remotes/" + remote + "/" + mergeBranch, nil
+}
+
+// shallowCommits returns the commits whose parents are missing because the repository is a shallow clone.
+func (r *Repository) shallowCommits() (map[Hash]struct{}, error) {
+	f, err := os.Open(filepath.Join(r.commonDir, "shallow"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	result := map[Hash]struct{}{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hash, err := ParseHash(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shallow commit %q: %w", line, err)
+		}
+		result[hash] = struct{}{}
+	}
+
+	return result, scanner.Err()
+}
@@ -0,0 +1,78 @@
+package git_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/command"
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNativeHistory_Status(t *testing.T) {
+	origin := gittest.NewRepo(t)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 10 {
+		origin.Commit(gittest.Commit{
+			Date:    base.AddDate(0, 0, i),
+			Message: fmt.Sprintf("commit number %d", i),
+			Files:   map[string]string{"file.txt": fmt.Sprint(i)},
+		})
+	}
+
+	clone := origin.Clone("--depth=3")
+	open := func() *git.NativeHistory {
+		repo, err := git.FindRepository(clone.Dir)
+		require.NoError(t, err)
+		return git.NewNativeHistory(repo)
+	}
+
+	t.Run("shallow history", func(t *testing.T) {
+		expected, err := git.CLIHistory{Runner: command.ExecRunner{Dir: clone.Dir}}.GetCommits("", base, base.AddDate(1, 0, 0))
+		require.NoError(t, err)
+		actual, err := open().GetCommits("", base, base.AddDate(1, 0, 0))
+		require.NoError(t, err)
+		assert.Len(t, actual, 3)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		status, err := open().Status("HEAD")
+		require.NoError(t, err)
+		status.NewestCommit = status.NewestCommit.UTC()
+		assert.Equal(t, git.RefStatus{
+			Ref:          "refs/heads/main",
+			Upstream:     "refs/remotes/origin/main",
+			NewestCommit: base.AddDate(0, 0, 9),
+			Shallow:      true,
+		}, status)
+	})
+
+	t.Run("ahead and behind", func(t *testing.T) {
+		clone.Commit(gittest.Commit{Date: base.AddDate(0, 0, 20), Message: "local change"})
+
+		status, err := open().Status("main")
+		require.NoError(t, err)
+		assert.True(t, status.Ahead)
+		assert.False(t, status.Behind)
+
+		origin.Commit(gittest.Commit{Date: base.AddDate(0, 0, 21), Message: "remote change"})
+		clone.Git(time.Time{}, "fetch", "--quiet")
+
+		status, err = open().Status("main")
+		require.NoError(t, err)
+		assert.True(t, status.Ahead)
+		assert.True(t, status.Behind)
+	})
+
+	t.Run("remote-tracking ref", func(t *testing.T) {
+		status, err := open().Status("origin/main")
+		require.NoError(t, err)
+		assert.Equal(t, "refs/remotes/origin/main", status.Ref)
+		assert.Empty(t, status.Upstream)
+		assert.Equal(t, base.AddDate(0, 0, 21), status.NewestCommit.UTC())
+	})
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// trailerPattern matches a "Key: value" trailer line.
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9-]+)\s*:\s*(.*)$`)
+
+type trailer struct {
+	key   string
+	value string
+}
+
+// parseTrailers parses the trailers at the end of a commit message.
+// This follows the same rules as git-interpret-trailers: the trailers are the last paragraph of the message,
+// which either must consist only of trailers or be at least 25% trailers with at least one generated by git.
+func parseTrailers(message string) []trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		// The subject is never a trailer.
+		return nil
+	}
+
+	var result []trailer
+	var numLines, numOther int
+	hasGitTrailer := false
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		numLines++
+
+		if len(result) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			// Continuation of the previous trailer.
+			result[len(result)-1].value += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		match := trailerPattern.FindStringSubmatch(line)
+		if match == nil {
+			numOther++
+			continue
+		}
+
+		result = append(result, trailer{key: match[1], value: strings.TrimSpace(match[2])})
+		if strings.EqualFold(match[1], "Signed-off-by") {
+			hasGitTrailer = true
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	if numOther > 0 && !(hasGitTrailer && numOther*4 <= numLines*3) {
+		return nil
+	}
+
+	return result
+}
+
+// parseCoAuthors returns everyone credited in a "Co-authored-by" trailer.
+func parseCoAuthors(message string) []Identity {
+	var result []Identity
+	for _, t := range parseTrailers(message) {
+		if !strings.EqualFold(t.key, "Co-authored-by") {
+			continue
+		}
+
+		name, email, _, ok := cutIdentity(t.value)
+		if !ok || email == "" {
+			continue
+		}
+		result = append(result, Identity{Name: name, Email: email})
+	}
+
+	return result
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCoAuthors(t *testing.T) {
+	tests := []struct {
+		desc    string
+		message string
+		exp     []Identity
+	}{{
+		desc:    "subject only",
+		message: "Co-authored-by: Joe <joe@example.com>",
+	}, {
+		desc:    "no trailers",
+		message: "Fix the thing\n\nThis was broken.\n",
+	}, {
+		desc:    "co-authors",
+		message: "Fix the thing\n\nThis was broken.\n\nCo-authored-by: Joe <joe@example.com>\nco-authored-by: Jane Doe <jane@example.com>\n",
+		exp: []Identity{
+			{Name: "Joe", Email: "joe@example.com"},
+			{Name: "Jane Doe", Email: "jane@example.com"},
+		},
+	}, {
+		desc:    "last paragraph isn't trailers",
+		message: "Fix the thing\n\nCo-authored-by: Joe <joe@example.com>\n\nJust kidding.",
+	}, {
+		desc:    "mixed with other text and a git trailer",
+		message: "Fix the thing\n\nCo-authored-by: Joe <joe@example.com>\nSigned-off-by: Jane <jane@example.com>\nsome other text\n",
+		exp: []Identity{
+			{Name: "Joe", Email: "joe@example.com"},
+		},
+	}, {
+		desc:    "mixed with other text and no git trailer",
+		message: "Fix the thing\n\nCo-authored-by: Joe <joe@example.com>\nsome other text\n",
+	}, {
+		desc:    "invalid identity",
+		message: "Fix the thing\n\nCo-authored-by: Joe\n",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.exp, parseCoAuthors(tc.message))
+		})
+	}
+}
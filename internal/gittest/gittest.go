@@ -0,0 +1,114 @@
+// Package gittest builds throwaway git repositories and in-memory histories for tests.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Repo is a git repository in a temporary directory that's removed when the test finishes.
+type Repo struct {
+	t   testing.TB
+	Dir string
+}
+
+// Commit describes a commit to make.
+type Commit struct {
+	// Author is the author in "Name <email>" format.
+	Author string
+	// Date is used as both the author and committer date.
+	Date    time.Time
+	Message string
+	// Files maps the paths of files to write before committing to their contents.
+	Files map[string]string
+}
+
+// NewRepo initializes an empty repository with a "main" branch. The test is skipped if git isn't installed.
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	r := &Repo{t: t, Dir: t.TempDir()}
+	r.Git(time.Time{}, "init", "--quiet", "--initial-branch=main")
+
+	return r
+}
+
+// Clone clones the repository into a new temporary directory. Extra arguments are passed to "git clone".
+func (r *Repo) Clone(args ...string) *Repo {
+	r.t.Helper()
+
+	clone := &Repo{t: r.t, Dir: r.t.TempDir()}
+	// Cloning with a file URL is needed for options like --depth to work.
+	r.Git(time.Time{}, append(append([]string{"clone", "--quiet"}, args...), "file://"+r.Dir, clone.Dir)...)
+
+	return clone
+}
+
+// Git runs git in the repository and returns its output. The user's git config is ignored and the committer is
+// fixed so that the results are reproducible. If date isn't zero, it's used as the author and committer date.
+func (r *Repo) Git(date time.Time, args ...string) string {
+	r.t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Author",
+		"GIT_AUTHOR_EMAIL=author@example.com",
+		"GIT_COMMITTER_NAME=Committer",
+		"GIT_COMMITTER_EMAIL=committer@example.com",
+	)
+	if !date.IsZero() {
+		cmd.Env = append(cmd.Env,
+			"GIT_COMMITTER_DATE="+date.Format(time.RFC3339),
+			"GIT_AUTHOR_DATE="+date.Format(time.RFC3339),
+		)
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+
+	return string(out)
+}
+
+// WriteFile writes a file in the repository's working tree, creating its parent directories.
+func (r *Repo) WriteFile(path, contents string) {
+	r.t.Helper()
+
+	fullPath := filepath.Join(r.Dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(fullPath, []byte(contents), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// Commit writes the commit's files, commits every change in the working tree, and returns the new commit's hash.
+func (r *Repo) Commit(c Commit) string {
+	r.t.Helper()
+
+	for path, contents := range c.Files {
+		r.WriteFile(path, contents)
+	}
+
+	args := []string{"commit", "--quiet", "--allow-empty", "--message=" + c.Message}
+	if c.Author != "" {
+		args = append(args, "--author="+c.Author)
+	}
+	r.Git(c.Date, "add", "--all")
+	r.Git(c.Date, args...)
+
+	return strings.TrimSpace(r.Git(time.Time{}, "rev-parse", "HEAD"))
+}
@@ -0,0 +1,49 @@
+package gittest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+)
+
+// History is an in-memory history for tests that don't need a real repository. Anything it doesn't know about is an
+// error.
+type History struct {
+	// Commits are the commits returned by GetCommits, newest first. Every ref has the same commits.
+	Commits []git.Commit
+	// Revisions are the times returned by RevisionTime, by revision.
+	Revisions map[string]time.Time
+	// Details are the details returned by CommitDetails, by commit hash.
+	Details map[string]git.CommitDetails
+}
+
+var _ git.History = History{}
+
+// GetCommits returns the commits that were committed between start and end.
+func (h History) GetCommits(ref string, start, end time.Time) ([]git.Commit, error) {
+	var result []git.Commit
+	for _, commit := range h.Commits {
+		if !commit.CommitTime.Before(start) && !commit.CommitTime.After(end) {
+			result = append(result, commit)
+		}
+	}
+
+	return result, nil
+}
+
+func (h History) RevisionTime(rev string) (time.Time, error) {
+	if t, ok := h.Revisions[rev]; ok {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unknown revision %q", rev)
+}
+
+func (h History) CommitDetails(commit git.Commit) (git.CommitDetails, error) {
+	if details, ok := h.Details[commit.Hash]; ok {
+		return details, nil
+	}
+
+	return git.CommitDetails{}, fmt.Errorf("unknown commit %s", commit.Hash)
+}
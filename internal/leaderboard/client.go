@@ -0,0 +1,47 @@
+package leaderboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Submit sends a result to the leaderboard server at the given base URL, e.g. "http://leaderboard.example.com:8080".
+func Submit(serverURL string, submission Submission) error {
+	body, err := json.Marshal(submission)
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(serverURL, "/") + "/api/results"
+	response, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error submitting result: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusCreated {
+		return nil
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+	var errResponse errorResponse
+	if json.Unmarshal(responseBody, &errResponse) != nil || errResponse.Error == "" {
+		return fmt.Errorf("the leaderboard responded with %s", response.Status)
+	}
+	if response.StatusCode == http.StatusConflict {
+		return ErrAlreadySubmitted
+	}
+
+	return errors.New(errResponse.Error)
+}
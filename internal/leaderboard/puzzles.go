@@ -0,0 +1,65 @@
+package leaderboard
+
+import (
+	"sync"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/game"
+)
+
+// DailyPuzzles builds the daily games of a schedule that results are submitted for so that their fingerprints can be
+// checked. Results are only accepted for the current and previous games, so at most two are built each day.
+type DailyPuzzles struct {
+	schedule game.Schedule
+	build    func(day time.Time) (game.Puzzle, error)
+
+	mu sync.Mutex
+	// fingerprints caches the fingerprint of each game, since building a game can be slow.
+	fingerprints map[int]string
+}
+
+var _ Puzzles = (*DailyPuzzles)(nil)
+
+// NewDailyPuzzles returns the daily games of the schedule, which are built for their day by build.
+func NewDailyPuzzles(schedule game.Schedule, build func(day time.Time) (game.Puzzle, error)) *DailyPuzzles {
+	return &DailyPuzzles{
+		schedule:     schedule,
+		build:        build,
+		fingerprints: map[int]string{},
+	}
+}
+
+// Add adds a daily game that's already been built, so that it doesn't need to be built again.
+func (p *DailyPuzzles) Add(puzzleNumber int, puzzle game.Puzzle) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.fingerprints[puzzleNumber] = puzzle.Fingerprint()
+}
+
+func (p *DailyPuzzles) Current() int {
+	return game.PuzzleNumber(p.schedule.Today())
+}
+
+func (p *DailyPuzzles) Fingerprint(puzzleNumber int) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if fingerprint, ok := p.fingerprints[puzzleNumber]; ok {
+		return fingerprint, nil
+	}
+
+	puzzle, err := p.build(p.schedule.PuzzleDay(puzzleNumber))
+	if err != nil {
+		return "", err
+	}
+	// Forget the games that results can no longer be submitted for.
+	for cached := range p.fingerprints {
+		if cached < p.Current()-maxPuzzleAge {
+			delete(p.fingerprints, cached)
+		}
+	}
+	p.fingerprints[puzzleNumber] = puzzle.Fingerprint()
+
+	return puzzle.Fingerprint(), nil
+}
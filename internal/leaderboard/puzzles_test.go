@@ -0,0 +1,78 @@
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/game"
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyPuzzles(t *testing.T) {
+	schedule := game.DefaultSchedule()
+	var commits []git.Commit
+	for _, name := range []string{"alice", "bob"} {
+		for i := range 4 {
+			commits = append(commits, git.Commit{
+				Hash:        fmt.Sprintf("%s%d", name, i),
+				AuthorName:  name,
+				AuthorEmail: name + "@example.com",
+				SubjectLine: fmt.Sprintf("Make change number %d", i),
+			})
+		}
+	}
+
+	buildPuzzle := func(puzzleNumber int) (game.Puzzle, error) {
+		return game.BuildPuzzle(
+			game.WithCommits(commits),
+			game.WithRandomSource(rand.NewSource(int64(puzzleNumber))),
+			game.WithAuthorBias(3.5),
+		)
+	}
+
+	var built []int
+	puzzles := NewDailyPuzzles(schedule, func(day time.Time) (game.Puzzle, error) {
+		puzzleNumber := game.PuzzleNumber(day)
+		if puzzleNumber == 1 {
+			return game.Puzzle{}, errors.New("no commits")
+		}
+		built = append(built, puzzleNumber)
+
+		return buildPuzzle(puzzleNumber)
+	})
+	current := puzzles.Current()
+	assert.Equal(t, game.PuzzleNumber(schedule.Today()), current)
+
+	// Games that were already built aren't built again.
+	today, err := buildPuzzle(current)
+	require.NoError(t, err)
+	puzzles.Add(current, today)
+	fingerprint, err := puzzles.Fingerprint(current)
+	require.NoError(t, err)
+	assert.Equal(t, today.Fingerprint(), fingerprint)
+	assert.Empty(t, built)
+
+	// Each game is only built once while results can be submitted for it.
+	_, err = puzzles.Fingerprint(current - 1)
+	require.NoError(t, err)
+	_, err = puzzles.Fingerprint(current - 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{current - 1}, built)
+
+	// Older games are forgotten once another game is built.
+	_, err = puzzles.Fingerprint(current - 5)
+	require.NoError(t, err)
+	_, err = puzzles.Fingerprint(current - 6)
+	require.NoError(t, err)
+	_, err = puzzles.Fingerprint(current - 5)
+	require.NoError(t, err)
+	assert.Equal(t, []int{current - 1, current - 5, current - 6, current - 5}, built)
+
+	_, err = puzzles.Fingerprint(1)
+	assert.EqualError(t, err, "no commits")
+}
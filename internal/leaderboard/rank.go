@@ -0,0 +1,117 @@
+package leaderboard
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Standing is a player's place on the leaderboard.
+type Standing struct {
+	Player string `json:"player"`
+	Played int    `json:"played"`
+	Won    int    `json:"won"`
+	// CurrentStreak is the number of consecutive daily games won, up to the current or previous game.
+	CurrentStreak int `json:"current_streak"`
+	MaxStreak     int `json:"max_streak"`
+	// AverageGuesses is the average number of guesses taken per game. A lost game counts as one more guess than
+	// the game had stages, so that losing is always worse than winning on the last guess.
+	AverageGuesses float64 `json:"average_guesses"`
+}
+
+// Rank computes every player's standing from submissions sorted by puzzle number. The players are ranked by their
+// current streak, then by their average guesses. currentPuzzle is the number of the current daily game.
+func Rank(submissions []Submission, currentPuzzle int) []Standing {
+	type progress struct {
+		standing     Standing
+		totalGuesses int
+		streak       int
+		lastWin      int
+	}
+
+	byPlayer := map[string]*progress{}
+	var players []string
+	for _, submission := range submissions {
+		p, ok := byPlayer[submission.Player]
+		if !ok {
+			p = &progress{standing: Standing{Player: submission.Player}}
+			byPlayer[submission.Player] = p
+			players = append(players, submission.Player)
+		}
+
+		p.standing.Played++
+		if !submission.Won() {
+			p.totalGuesses += submission.NumStages + 1
+			p.streak = 0
+			continue
+		}
+
+		p.standing.Won++
+		p.totalGuesses += submission.SolvedStage
+		if p.streak > 0 && p.lastWin == submission.PuzzleNumber-1 {
+			p.streak++
+		} else {
+			p.streak = 1
+		}
+		p.lastWin = submission.PuzzleNumber
+		p.standing.MaxStreak = max(p.standing.MaxStreak, p.streak)
+	}
+
+	var result []Standing
+	for _, player := range players {
+		p := byPlayer[player]
+		// The streak is only current if it hasn't been broken by missing a game.
+		if p.streak > 0 && p.lastWin >= currentPuzzle-1 {
+			p.standing.CurrentStreak = p.streak
+		}
+		p.standing.AverageGuesses = float64(p.totalGuesses) / float64(p.standing.Played)
+		result = append(result, p.standing)
+	}
+
+	slices.SortFunc(result, func(a, b Standing) int {
+		return cmp.Or(
+			cmp.Compare(b.CurrentStreak, a.CurrentStreak),
+			cmp.Compare(a.AverageGuesses, b.AverageGuesses),
+			strings.Compare(a.Player, b.Player),
+		)
+	})
+
+	return result
+}
+
+// GameResults summarizes how the players did in one daily game.
+type GameResults struct {
+	Players int
+	Solved  int
+	// Distribution is how many players solved the game at each stage.
+	Distribution []int
+	// AverageGuesses is the average number of guesses taken by the players that solved the game.
+	AverageGuesses float64
+}
+
+// SummarizeGame summarizes the submissions for the daily game with the given number, which had numStages stages.
+func SummarizeGame(submissions []Submission, puzzleNumber, numStages int) GameResults {
+	result := GameResults{Distribution: make([]int, numStages)}
+	totalGuesses := 0
+	for _, submission := range submissions {
+		if submission.PuzzleNumber != puzzleNumber {
+			continue
+		}
+
+		result.Players++
+		if !submission.Won() {
+			continue
+		}
+
+		result.Solved++
+		totalGuesses += submission.SolvedStage
+		if submission.SolvedStage <= len(result.Distribution) {
+			result.Distribution[submission.SolvedStage-1]++
+		}
+	}
+	if result.Solved > 0 {
+		result.AverageGuesses = float64(totalGuesses) / float64(result.Solved)
+	}
+
+	return result
+}
@@ -0,0 +1,71 @@
+package leaderboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRank(t *testing.T) {
+	won := func(player string, puzzleNumber, stage int) Submission {
+		return Submission{Player: player, PuzzleNumber: puzzleNumber, Guesses: stage, SolvedStage: stage, NumStages: 4}
+	}
+	lost := func(player string, puzzleNumber int) Submission {
+		return Submission{Player: player, PuzzleNumber: puzzleNumber, Guesses: 4, NumStages: 4}
+	}
+
+	submissions := []Submission{
+		won("alice", 1, 1),
+		won("bob", 1, 4),
+		won("carol", 1, 2),
+		won("alice", 2, 1),
+		won("bob", 2, 4),
+		lost("carol", 2),
+		won("bob", 3, 3),
+		won("carol", 3, 2),
+		won("bob", 4, 2),
+		won("carol", 4, 1),
+	}
+
+	assert.Equal(t, []Standing{{
+		Player:         "bob",
+		Played:         4,
+		Won:            4,
+		CurrentStreak:  4,
+		MaxStreak:      4,
+		AverageGuesses: 3.25,
+	}, {
+		Player:         "carol",
+		Played:         4,
+		Won:            3,
+		CurrentStreak:  2,
+		MaxStreak:      2,
+		AverageGuesses: 2.5,
+	}, {
+		// Alice missed yesterday's game, so her streak isn't current.
+		Player:         "alice",
+		Played:         2,
+		Won:            2,
+		CurrentStreak:  0,
+		MaxStreak:      2,
+		AverageGuesses: 1,
+	}}, Rank(submissions, 5))
+}
+
+func TestSummarizeGame(t *testing.T) {
+	submissions := []Submission{
+		{Player: "alice", PuzzleNumber: 9, Guesses: 1, SolvedStage: 1, NumStages: 4},
+		{Player: "alice", PuzzleNumber: 10, Guesses: 2, SolvedStage: 2, NumStages: 4},
+		{Player: "bob", PuzzleNumber: 10, Guesses: 4, NumStages: 4},
+		{Player: "carol", PuzzleNumber: 10, Guesses: 3, SolvedStage: 3, NumStages: 4},
+		{Player: "dave", PuzzleNumber: 10, Guesses: 3, SolvedStage: 3, NumStages: 4},
+	}
+
+	assert.Equal(t, GameResults{
+		Players:        4,
+		Solved:         3,
+		Distribution:   []int{0, 1, 2, 0},
+		AverageGuesses: 8.0 / 3,
+	}, SummarizeGame(submissions, 10, 4))
+	assert.Equal(t, GameResults{Distribution: []int{0, 0, 0, 0}}, SummarizeGame(submissions, 11, 4))
+}
@@ -0,0 +1,184 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Puzzles looks up the daily games that results are submitted for.
+type Puzzles interface {
+	// Current returns the number of the current daily game.
+	Current() int
+	// Fingerprint returns the fingerprint of the daily game with the given number. It's only called for games that
+	// results are accepted for.
+	Fingerprint(puzzleNumber int) (string, error)
+}
+
+// maxPuzzleAge is how many games before the current one that results are still accepted for. This lets players submit
+// a game they finished just before it rolled over, without the leaderboard having to build any older game on request.
+const maxPuzzleAge = 1
+
+// Server collects results over HTTP. Results are submitted to "POST /api/results" and the standings are served as
+// JSON at "GET /api/leaderboard" and as a web page at "GET /".
+type Server struct {
+	store   *Store
+	puzzles Puzzles
+
+	mux *http.ServeMux
+}
+
+var _ http.Handler = (*Server)(nil)
+
+func NewServer(store *Store, puzzles Puzzles) *Server {
+	s := &Server{
+		store:   store,
+		puzzles: puzzles,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /{$}", s.handleGetPage)
+	s.mux.HandleFunc("GET /api/leaderboard", s.handleGetLeaderboard)
+	s.mux.HandleFunc("POST /api/results", s.handleSubmit)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var submission Submission
+	err := json.NewDecoder(r.Body).Decode(&submission)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request: " + err.Error()})
+		return
+	}
+
+	err = s.validate(submission)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	fingerprint, err := s.puzzles.Fingerprint(submission.PuzzleNumber)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: fmt.Sprintf("failed to build game #%d: %s", submission.PuzzleNumber, err.Error())})
+		return
+	}
+	// A different fingerprint means that the player had a different game, so their result can't be compared. The
+	// leaderboard's fingerprint isn't included in the error, since it could be resubmitted without playing the game.
+	if submission.Fingerprint != fingerprint {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: fmt.Sprintf("the fingerprint %q doesn't match the leaderboard's game #%d. Make sure your git history is up to date", submission.Fingerprint, submission.PuzzleNumber)})
+		return
+	}
+
+	err = s.store.Add(submission)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrAlreadySubmitted) {
+			status = http.StatusConflict
+		}
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, submission)
+}
+
+// validate checks that the submission is a possible result for a game that's been released.
+func (s *Server) validate(submission Submission) error {
+	switch {
+	case strings.TrimSpace(submission.Player) == "":
+		return errors.New("the player is required")
+	case submission.PuzzleNumber > s.puzzles.Current():
+		return fmt.Errorf("game #%d hasn't been released", submission.PuzzleNumber)
+	case submission.PuzzleNumber < max(1, s.puzzles.Current()-maxPuzzleAge):
+		return fmt.Errorf("game #%d is too old, results are only accepted for the current and previous games", submission.PuzzleNumber)
+	case submission.NumStages < 1:
+		return errors.New("the number of stages must be at least 1")
+	case submission.Guesses < 1 || submission.Guesses > submission.NumStages:
+		return fmt.Errorf("the number of guesses must be between 1 and %d", submission.NumStages)
+	case submission.Won() && submission.SolvedStage != submission.Guesses:
+		return errors.New("the solved stage must be the last guess")
+	case !submission.Won() && (submission.SolvedStage < 0 || submission.Guesses != submission.NumStages):
+		return errors.New("an unsolved game must use every guess")
+	}
+
+	return nil
+}
+
+func (s *Server) handleGetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	standings, err := s.standings()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, standings)
+}
+
+var pageTemplate = template.Must(template.New("leaderboard").Funcs(template.FuncMap{"inc": func(i int) int { return i + 1 }}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>gauthordle leaderboard</title>
+<style>
+body { font-family: monospace; background: #1e1e1e; color: #ddd; margin: 2em; }
+h1 { color: #e5c07b; }
+th { color: #98c379; text-align: left; }
+th, td { padding: 0.25em 1em; }
+</style>
+</head>
+<body>
+<h1>gauthordle leaderboard</h1>
+{{if .}}
+<table>
+<tr><th>#</th><th>Player</th><th>Current streak</th><th>Average guesses</th><th>Played</th><th>Won</th><th>Max streak</th></tr>
+{{range $i, $s := .}}<tr><td>{{inc $i}}</td><td>{{$s.Player}}</td><td>{{$s.CurrentStreak}}</td><td>{{printf "%.2f" $s.AverageGuesses}}</td><td>{{$s.Played}}</td><td>{{$s.Won}}</td><td>{{$s.MaxStreak}}</td></tr>
+{{end}}</table>
+{{else}}
+<p>No results have been submitted yet.</p>
+{{end}}
+</body>
+</html>
+`))
+
+func (s *Server) handleGetPage(w http.ResponseWriter, r *http.Request) {
+	standings, err := s.standings()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = pageTemplate.Execute(w, standings)
+}
+
+func (s *Server) standings() ([]Standing, error) {
+	submissions, err := s.store.Submissions()
+	if err != nil {
+		return nil, err
+	}
+
+	standings := Rank(submissions, s.puzzles.Current())
+	if standings == nil {
+		standings = []Standing{}
+	}
+
+	return standings, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
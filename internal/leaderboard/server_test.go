@@ -0,0 +1,95 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedPuzzles has a fingerprint for every game up to the current one.
+type fixedPuzzles int
+
+func (p fixedPuzzles) Current() int {
+	return int(p)
+}
+
+func (p fixedPuzzles) Fingerprint(puzzleNumber int) (string, error) {
+	return fmt.Sprintf("fingerprint%d", puzzleNumber), nil
+}
+
+func TestServer(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", "leaderboard.json"))
+	server := httptest.NewServer(NewServer(store, fixedPuzzles(10)))
+	defer server.Close()
+
+	valid := Submission{Player: "alice", PuzzleNumber: 10, Fingerprint: "fingerprint10", Guesses: 2, SolvedStage: 2, NumStages: 4}
+	require.NoError(t, Submit(server.URL+"/", valid))
+	require.NoError(t, Submit(server.URL, Submission{Player: "bob", PuzzleNumber: 9, Fingerprint: "fingerprint9", Guesses: 4, NumStages: 4}))
+
+	// Only the first result for a game counts.
+	retry := valid
+	retry.Guesses, retry.SolvedStage = 1, 1
+	assert.ErrorIs(t, Submit(server.URL, retry), ErrAlreadySubmitted)
+
+	invalid := []struct {
+		desc       string
+		submission Submission
+		expError   string
+	}{{
+		desc:       "fingerprint mismatch",
+		submission: Submission{Player: "carol", PuzzleNumber: 10, Fingerprint: "fingerprint9", Guesses: 1, SolvedStage: 1, NumStages: 4},
+		expError:   `the fingerprint "fingerprint9" doesn't match the leaderboard's game #10. Make sure your git history is up to date`,
+	}, {
+		desc:       "future game",
+		submission: Submission{Player: "carol", PuzzleNumber: 11, Fingerprint: "fingerprint11", Guesses: 1, SolvedStage: 1, NumStages: 4},
+		expError:   "game #11 hasn't been released",
+	}, {
+		desc:       "old game",
+		submission: Submission{Player: "carol", PuzzleNumber: 8, Fingerprint: "fingerprint8", Guesses: 1, SolvedStage: 1, NumStages: 4},
+		expError:   "game #8 is too old, results are only accepted for the current and previous games",
+	}, {
+		desc:       "missing player",
+		submission: Submission{PuzzleNumber: 10, Fingerprint: "fingerprint10", Guesses: 1, SolvedStage: 1, NumStages: 4},
+		expError:   "the player is required",
+	}, {
+		desc:       "too many guesses",
+		submission: Submission{Player: "carol", PuzzleNumber: 10, Fingerprint: "fingerprint10", Guesses: 5, SolvedStage: 5, NumStages: 4},
+		expError:   "the number of guesses must be between 1 and 4",
+	}, {
+		desc:       "gave up early",
+		submission: Submission{Player: "carol", PuzzleNumber: 10, Fingerprint: "fingerprint10", Guesses: 2, NumStages: 4},
+		expError:   "an unsolved game must use every guess",
+	}}
+	for _, tc := range invalid {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.EqualError(t, Submit(server.URL, tc.submission), tc.expError)
+		})
+	}
+
+	response, err := http.Get(server.URL + "/api/leaderboard")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	var standings []Standing
+	require.NoError(t, json.Unmarshal(body, &standings))
+	assert.Equal(t, []Standing{
+		{Player: "alice", Played: 1, Won: 1, CurrentStreak: 1, MaxStreak: 1, AverageGuesses: 2},
+		{Player: "bob", Played: 1, AverageGuesses: 5},
+	}, standings)
+
+	page, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer page.Body.Close()
+	pageBody, err := io.ReadAll(page.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(pageBody), "<td>alice</td>")
+}
@@ -0,0 +1,128 @@
+// Package leaderboard collects the results of a team's daily games and ranks the players.
+package leaderboard
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/josephnaberhaus/gauthordle/internal/stats"
+)
+
+// Submission is a player's result for a daily game.
+type Submission struct {
+	Player       string `json:"player"`
+	PuzzleNumber int    `json:"puzzle_number"`
+	// Fingerprint is the fingerprint of the player's game. It must match the fingerprint of the server's game.
+	Fingerprint string `json:"fingerprint"`
+	// Guesses is how many guesses the player made.
+	Guesses int `json:"guesses"`
+	// SolvedStage is the 1-indexed stage the puzzle was solved at, or 0 if it wasn't solved.
+	SolvedStage int `json:"solved_stage"`
+	// NumStages is how many stages the puzzle had.
+	NumStages int `json:"num_stages"`
+}
+
+func (s Submission) Won() bool {
+	return s.SolvedStage > 0
+}
+
+// ErrAlreadySubmitted is returned when a player submits more than one result for the same game.
+var ErrAlreadySubmitted = errors.New("a result was already submitted for this game")
+
+// Store is a file holding every submitted result.
+type Store struct {
+	path string
+
+	// mu serializes writes, since the server handles submissions concurrently.
+	mu sync.Mutex
+}
+
+// DefaultPath returns where results are stored, next to the player's own games.
+func DefaultPath() (string, error) {
+	gamesPath, err := stats.DefaultPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(filepath.Dir(gamesPath), "leaderboard.json"), nil
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Submissions returns every submitted result, sorted by puzzle number and then player.
+func (s *Store) Submissions() ([]Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read()
+}
+
+// Add saves a result. Only the first result each player submits for a game is kept, so ErrAlreadySubmitted is
+// returned for any others.
+func (s *Store) Add(submission Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submissions, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	alreadySubmitted := slices.ContainsFunc(submissions, func(other Submission) bool {
+		return other.Player == submission.Player && other.PuzzleNumber == submission.PuzzleNumber
+	})
+	if alreadySubmitted {
+		return ErrAlreadySubmitted
+	}
+	submissions = append(submissions, submission)
+
+	contents, err := json.MarshalIndent(submissions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that an interrupted write can't lose the existing results.
+	tmpPath := s.path + ".tmp"
+	err = os.WriteFile(tmpPath, contents, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
+
+// read reads the submissions. The lock must be held.
+func (s *Store) read() ([]Submission, error) {
+	contents, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var submissions []Submission
+	err = json.Unmarshal(contents, &submissions)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(submissions, func(a, b Submission) int {
+		return cmp.Or(cmp.Compare(a.PuzzleNumber, b.PuzzleNumber), strings.Compare(a.Player, b.Player))
+	})
+
+	return submissions, nil
+}
@@ -0,0 +1,224 @@
+// Package server serves a puzzle as a web page so that it can be played in a browser.
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/josephnaberhaus/gauthordle/internal/game"
+)
+
+//go:embed static
+var static embed.FS
+
+// Server plays a single puzzle over HTTP. It serves the web UI at "/" and a JSON API under "/api/".
+type Server struct {
+	puzzle       game.Puzzle
+	puzzleNumber int
+	repository   string
+	onFinish     func(game.Result)
+
+	mux *http.ServeMux
+
+	mu      sync.Mutex
+	session *game.Session
+}
+
+var _ http.Handler = (*Server)(nil)
+
+type Option func(*Server)
+
+// WithPuzzleNumber sets the daily puzzle number shown in the UI and share string.
+// Random games don't have a number and don't get a share string.
+func WithPuzzleNumber(puzzleNumber int) Option {
+	return func(s *Server) {
+		s.puzzleNumber = puzzleNumber
+	}
+}
+
+// WithRepository sets the name of the repository shown in the UI and share string.
+func WithRepository(repository string) Option {
+	return func(s *Server) {
+		s.repository = repository
+	}
+}
+
+// WithResultHandler sets a function that's called with the result once the game is over.
+func WithResultHandler(onFinish func(game.Result)) Option {
+	return func(s *Server) {
+		s.onFinish = onFinish
+	}
+}
+
+func New(puzzle game.Puzzle, opts ...Option) *Server {
+	s := &Server{
+		puzzle:  puzzle,
+		session: game.NewSession(puzzle),
+		mux:     http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	staticFiles, err := fs.Sub(static, "static")
+	if err != nil {
+		// The embedded files are always there.
+		panic(err)
+	}
+	s.mux.Handle("GET /", http.FileServerFS(staticFiles))
+	s.mux.HandleFunc("GET /api/puzzle", s.handleGetPuzzle)
+	s.mux.HandleFunc("GET /api/hints", s.handleGetHints)
+	s.mux.HandleFunc("POST /api/guess", s.handleGuess)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// State is the state of the game sent to the client.
+type State struct {
+	PuzzleNumber int           `json:"puzzle_number,omitempty"`
+	Repository   string        `json:"repository"`
+	NumStages    int           `json:"num_stages"`
+	Stage        int           `json:"stage"`
+	Clues        []game.Clue   `json:"clues"`
+	Hints        []game.Hint   `json:"hints"`
+	Authors      []game.Author `json:"authors"`
+	Guesses      []string      `json:"guesses"`
+	Finished     bool          `json:"finished"`
+	Won          bool          `json:"won"`
+	// Answer is only set once the game is finished.
+	Answer *game.Author `json:"answer,omitempty"`
+	// Share is the shareable result, which is only set once a daily game is finished.
+	Share string `json:"share,omitempty"`
+}
+
+type guessRequest struct {
+	Email string `json:"email"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleGetPuzzle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, s.state())
+}
+
+func (s *Server) handleGetHints(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, s.hints())
+}
+
+func (s *Server) handleGuess(w http.ResponseWriter, r *http.Request) {
+	// Browsers only let another site POST JSON after a CORS preflight, which the server never allows. Requiring JSON
+	// and a matching Origin stops other pages from using up the daily game.
+	if !isSameOrigin(r) {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "cross-origin requests aren't allowed"})
+		return
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, errorResponse{Error: "the request must be application/json"})
+		return
+	}
+
+	var request guessRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	result, err := s.session.Guess(request.Email)
+	if err != nil {
+		s.mu.Unlock()
+		status := http.StatusBadRequest
+		if errors.Is(err, game.ErrFinished) {
+			status = http.StatusConflict
+		}
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+	finished := s.session.Finished()
+	state := s.state()
+	s.mu.Unlock()
+
+	// The handler may be slow, e.g. posting to webhooks, so it's called without the lock. Only the guess that finishes
+	// the game gets here with finished set, so it's called once.
+	if finished && s.onFinish != nil {
+		s.onFinish(result)
+	}
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+// isSameOrigin returns whether the request came from the server's own pages. Requests without an Origin header, e.g.
+// from curl, aren't made by a browser on behalf of another site.
+func isSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return u.Host == r.Host
+}
+
+// state returns the state of the game. The lock must be held.
+func (s *Server) state() State {
+	gameResult := s.session.Result()
+	result := State{
+		PuzzleNumber: s.puzzleNumber,
+		Repository:   s.repository,
+		NumStages:    s.puzzle.NumStages(),
+		Stage:        s.session.CurrentStage(),
+		Hints:        s.hints(),
+		Guesses:      append([]string{}, gameResult.Guesses...),
+		Finished:     s.session.Finished(),
+		Won:          gameResult.Won,
+		Authors:      s.puzzle.Authors(),
+		Clues:        s.session.RevealedClues(),
+	}
+
+	// Never reveal the answer while the game can still be played.
+	if s.session.Finished() {
+		answer := s.puzzle.Answer()
+		result.Answer = &answer
+		if s.puzzleNumber != 0 {
+			result.Share = gameResult.ShareString(s.puzzleNumber, s.repository)
+		}
+	}
+
+	return result
+}
+
+// hints returns the hints revealed at the current stage. The lock must be held.
+func (s *Server) hints() []game.Hint {
+	// Always send a list, even before any hints are revealed.
+	return append([]game.Hint{}, s.session.RevealedHints()...)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josephnaberhaus/gauthordle/internal/game"
+	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPuzzle(t *testing.T) game.Puzzle {
+	t.Helper()
+
+	var commits []git.Commit
+	for _, name := range []string{"alice", "bob", "carol"} {
+		for i := range 5 {
+			commits = append(commits, git.Commit{
+				Hash:        fmt.Sprintf("%s%d", name, i),
+				AuthorName:  name,
+				AuthorEmail: name + "@example.com",
+				SubjectLine: fmt.Sprintf("Make change number %d", i),
+			})
+		}
+	}
+
+	puzzle, err := game.BuildPuzzle(
+		game.WithCommits(commits),
+		game.WithRandomSource(rand.NewSource(1)),
+		game.WithAuthorBias(3.5),
+	)
+	require.NoError(t, err)
+
+	return puzzle
+}
+
+func doRequest(t *testing.T, server http.Handler, method, path, body string) (int, string) {
+	t.Helper()
+
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	responseBody, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	return recorder.Code, string(responseBody)
+}
+
+func guess(t *testing.T, server http.Handler, email string) State {
+	t.Helper()
+
+	body, err := json.Marshal(guessRequest{Email: email})
+	require.NoError(t, err)
+	status, response := doRequest(t, server, http.MethodPost, "/api/guess", string(body))
+	require.Equal(t, http.StatusOK, status, response)
+
+	var state State
+	require.NoError(t, json.Unmarshal([]byte(response), &state))
+
+	return state
+}
+
+func TestServer_Lose(t *testing.T) {
+	puzzle := testPuzzle(t)
+	answer := puzzle.Answer()
+	var wrong string
+	for _, author := range puzzle.Authors() {
+		if author != answer {
+			wrong = author.Email
+		}
+	}
+
+	var finished []game.Result
+	server := New(puzzle,
+		WithPuzzleNumber(12),
+		WithRepository("repo"),
+		WithResultHandler(func(result game.Result) {
+			finished = append(finished, result)
+		}),
+	)
+
+	status, response := doRequest(t, server, http.MethodGet, "/api/puzzle", "")
+	require.Equal(t, http.StatusOK, status)
+	// The answer must not be sent before the game is over.
+	assert.NotContains(t, response, `"answer"`)
+	assert.Contains(t, response, `"puzzle_number":12`)
+	assert.Contains(t, response, `"num_stages":4`)
+	var state State
+	require.NoError(t, json.Unmarshal([]byte(response), &state))
+	assert.Equal(t, 0, state.Stage)
+	assert.Len(t, state.Clues, 1)
+	assert.Empty(t, state.Hints)
+	assert.Len(t, state.Authors, 3)
+
+	for stage := 1; stage < puzzle.NumStages(); stage++ {
+		state = guess(t, server, wrong)
+		assert.Equal(t, stage, state.Stage)
+		assert.Len(t, state.Clues, stage+1)
+		assert.Len(t, state.Hints, len(puzzle.Hints(stage)))
+		assert.False(t, state.Finished)
+		assert.Nil(t, state.Answer)
+		assert.Empty(t, state.Share)
+
+		_, hints := doRequest(t, server, http.MethodGet, "/api/hints", "")
+		assert.Contains(t, hints, "Number of commits")
+	}
+
+	state = guess(t, server, wrong)
+	assert.True(t, state.Finished)
+	assert.False(t, state.Won)
+	assert.Equal(t, &answer, state.Answer)
+	assert.True(t, strings.HasPrefix(state.Share, "gauthordle #12 repo X/4\n"), state.Share)
+
+	require.Len(t, finished, 1)
+	assert.Len(t, finished[0].Guesses, 4)
+
+	// No more guesses are allowed once the game is over.
+	status, _ = doRequest(t, server, http.MethodPost, "/api/guess", `{"email": "`+answer.Email+`"}`)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Len(t, finished, 1)
+}
+
+func TestServer_Win(t *testing.T) {
+	puzzle := testPuzzle(t)
+	var server *Server
+	var finishedState string
+	server = New(puzzle, WithResultHandler(func(game.Result) {
+		// The handler can use the server, since it's called without the lock held.
+		_, finishedState = doRequest(t, server, http.MethodGet, "/api/puzzle", "")
+	}))
+
+	state := guess(t, server, puzzle.Answer().Email)
+	assert.True(t, state.Finished)
+	assert.True(t, state.Won)
+	// Random games don't have a share string.
+	assert.Empty(t, state.Share)
+	assert.Contains(t, finishedState, `"finished":true`)
+}
+
+func TestServer_InvalidGuess(t *testing.T) {
+	server := New(testPuzzle(t))
+
+	status, _ := doRequest(t, server, http.MethodPost, "/api/guess", "not json")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = doRequest(t, server, http.MethodPost, "/api/guess", `{"email": "nobody@example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestServer_GuessFromAnotherSite(t *testing.T) {
+	puzzle := testPuzzle(t)
+	server := New(puzzle)
+	body := `{"email": "` + puzzle.Answer().Email + `"}`
+
+	tests := []struct {
+		desc        string
+		contentType string
+		origin      string
+		exp         int
+	}{{
+		desc:        "form post",
+		contentType: "application/x-www-form-urlencoded",
+		exp:         http.StatusUnsupportedMediaType,
+	}, {
+		desc:        "text post",
+		contentType: "text/plain",
+		exp:         http.StatusUnsupportedMediaType,
+	}, {
+		desc:        "another origin",
+		contentType: "application/json",
+		origin:      "https://evil.example.com",
+		exp:         http.StatusForbidden,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/api/guess", strings.NewReader(body))
+			request.Header.Set("Content-Type", tc.contentType)
+			if tc.origin != "" {
+				request.Header.Set("Origin", tc.origin)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.exp, recorder.Code)
+		})
+	}
+
+	// None of them used up the game, and the server's own page can still play.
+	request := httptest.NewRequest(http.MethodPost, "/api/guess", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	request.Header.Set("Origin", "http://"+request.Host)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"won":true`)
+}
+
+func TestServer_UI(t *testing.T) {
+	status, response := doRequest(t, New(testPuzzle(t)), http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, response, "<title>gauthordle</title>")
+}
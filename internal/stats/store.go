@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Game is the record of a single daily game.
+type Game struct {
+	// Repository identifies the repository the game was played in.
+	Repository string `json:"repository"`
+	// Team is the team the game was built for. Empty if the game wasn't for a team.
+	Team string `json:"team,omitempty"`
+	// Date is the day of the puzzle in time.DateOnly format.
+	Date string `json:"date"`
+	// Guesses are the e-mails of the authors guessed, in order.
+	Guesses []string `json:"guesses"`
+	// Won is whether the author was guessed.
+	Won bool `json:"won"`
+	// SolvedStage is the 1-indexed stage the puzzle was solved at, or 0 if it wasn't solved.
+	SolvedStage int `json:"solved_stage"`
+	// NumStages is how many stages the puzzle had.
+	NumStages int `json:"num_stages"`
+	// Replay is whether the game was played after its day was over, e.g. with --date or --puzzle. Replays don't count
+	// towards streaks.
+	Replay bool `json:"replay,omitempty"`
+}
+
+// Key identifies a daily game.
+type Key struct {
+	Repository string
+	Team       string
+	Date       time.Time
+}
+
+func (g Game) matches(key Key) bool {
+	return g.Repository == key.Repository && g.Team == key.Team && g.Date == key.Date.Format(time.DateOnly)
+}
+
+// Store is a file holding the record of every daily game played.
+type Store struct {
+	path string
+}
+
+// DefaultPath returns where games are stored, following the XDG base directory specification.
+func DefaultPath() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" || !filepath.IsAbs(dataHome) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(dataHome, "gauthordle", "games.json"), nil
+}
+
+// Open opens the store at the default path.
+func Open() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(path), nil
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Games returns every recorded game, sorted by date.
+func (s *Store) Games() ([]Game, error) {
+	contents, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var games []Game
+	err = json.Unmarshal(contents, &games)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(games, func(a, b Game) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return games, nil
+}
+
+// GamesFor returns the recorded games for a repository and team, sorted by date.
+func (s *Store) GamesFor(repository, team string) ([]Game, error) {
+	games, err := s.Games()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(games, func(g Game) bool {
+		return g.Repository != repository || g.Team != team
+	}), nil
+}
+
+// Find returns the game recorded for the key, if there is one.
+func (s *Store) Find(key Key) (Game, bool, error) {
+	games, err := s.Games()
+	if err != nil {
+		return Game{}, false, err
+	}
+
+	for _, game := range games {
+		if game.matches(key) {
+			return game, true, nil
+		}
+	}
+
+	return Game{}, false, nil
+}
+
+// Record saves a game, replacing any game already recorded for the same key.
+func (s *Store) Record(key Key, game Game) error {
+	games, err := s.Games()
+	if err != nil {
+		return err
+	}
+
+	game.Repository = key.Repository
+	game.Team = key.Team
+	game.Date = key.Date.Format(time.DateOnly)
+	games = slices.DeleteFunc(games, func(g Game) bool {
+		return g.matches(key)
+	})
+	games = append(games, game)
+
+	contents, err := json.MarshalIndent(games, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that an interrupted write can't lose the existing history.
+	tmpPath := s.path + ".tmp"
+	err = os.WriteFile(tmpPath, contents, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
@@ -0,0 +1,128 @@
+package stats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/output"
+)
+
+// Summary is the statistics for a set of games.
+type Summary struct {
+	Played int
+	Won    int
+	// CurrentStreak is the number of consecutive daily games won, up to today or yesterday.
+	CurrentStreak int
+	MaxStreak     int
+	// Distribution is the number of games solved at each stage. The first element is for the first stage.
+	Distribution []int
+	// Lost is the number of games that weren't solved.
+	Lost int
+}
+
+func (s Summary) WinPercent() int {
+	if s.Played == 0 {
+		return 0
+	}
+
+	return s.Won * 100 / s.Played
+}
+
+// Summarize computes the statistics of games sorted by date.
+func Summarize(games []Game, today time.Time) Summary {
+	var result Summary
+
+	var streak int
+	var lastWin time.Time
+	for _, game := range games {
+		date, err := time.ParseInLocation(time.DateOnly, game.Date, today.Location())
+		if err != nil {
+			// Ignore corrupted records rather than failing to show anything.
+			continue
+		}
+
+		result.Played++
+		for len(result.Distribution) < max(game.NumStages, game.SolvedStage) {
+			result.Distribution = append(result.Distribution, 0)
+		}
+
+		if !game.Won {
+			result.Lost++
+			if !game.Replay {
+				streak = 0
+			}
+			continue
+		}
+
+		result.Won++
+		if game.SolvedStage > 0 {
+			result.Distribution[game.SolvedStage-1]++
+		}
+
+		// Catching up on a missed day neither extends nor repairs a streak.
+		if game.Replay {
+			continue
+		}
+
+		if !lastWin.IsZero() && isNextDay(lastWin, date) && streak > 0 {
+			streak++
+		} else {
+			streak = 1
+		}
+		lastWin = date
+		result.MaxStreak = max(result.MaxStreak, streak)
+	}
+
+	// The streak is only current if it hasn't been broken by missing a day.
+	todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	if streak > 0 && (lastWin.Equal(todayDate) || isNextDay(lastWin, todayDate)) {
+		result.CurrentStreak = streak
+	}
+
+	return result
+}
+
+func isNextDay(before, after time.Time) bool {
+	next := time.Date(before.Year(), before.Month(), before.Day()+1, 0, 0, 0, 0, before.Location())
+	return next.Equal(after)
+}
+
+// Print shows the statistics in the terminal.
+func (s Summary) Print() {
+	output.PrintColorLn("Statistics", output.Yellow)
+	output.Ln()
+
+	printStat := func(name string, value string) {
+		output.PrintColor(fmt.Sprintf("%-16s", name), output.Green)
+		output.PrintColorLn(value, output.White)
+	}
+	printStat("Played", strconv.Itoa(s.Played))
+	printStat("Win %", strconv.Itoa(s.WinPercent()))
+	printStat("Current streak", strconv.Itoa(s.CurrentStreak))
+	printStat("Max streak", strconv.Itoa(s.MaxStreak))
+
+	output.Ln()
+	output.PrintColorLn("Guess distribution", output.Yellow)
+
+	const maxBarWidth = 30
+	largest := s.Lost
+	for _, count := range s.Distribution {
+		largest = max(largest, count)
+	}
+	printBar := func(label string, count int, color output.Color) {
+		width := 1
+		if largest > 0 {
+			width = max(1, count*maxBarWidth/largest)
+		}
+
+		output.PrintColor(label+" ", output.White)
+		output.PrintColor(strings.Repeat("█", width), color)
+		output.PrintColorLn(" "+strconv.Itoa(count), output.White)
+	}
+	for i, count := range s.Distribution {
+		printBar(strconv.Itoa(i+1), count, output.Green)
+	}
+	printBar("X", s.Lost, output.Red)
+}
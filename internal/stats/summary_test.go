@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	today := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc  string
+		games []Game
+		exp   Summary
+	}{{
+		desc: "no games",
+		exp:  Summary{},
+	}, {
+		desc: "streak broken by a loss",
+		games: []Game{
+			{Date: "2024-05-01", Won: true, SolvedStage: 1, NumStages: 4},
+			{Date: "2024-05-02", Won: true, SolvedStage: 2, NumStages: 4},
+			{Date: "2024-05-03", Won: true, SolvedStage: 2, NumStages: 4},
+			{Date: "2024-05-04", Won: false, NumStages: 4},
+			{Date: "2024-05-05", Won: true, SolvedStage: 4, NumStages: 4},
+		},
+		exp: Summary{
+			Played:       5,
+			Won:          4,
+			MaxStreak:    3,
+			Distribution: []int{1, 2, 0, 1},
+			Lost:         1,
+		},
+	}, {
+		desc: "streak broken by a missed day",
+		games: []Game{
+			{Date: "2024-05-06", Won: true, SolvedStage: 1, NumStages: 4},
+			{Date: "2024-05-08", Won: true, SolvedStage: 1, NumStages: 4},
+			{Date: "2024-05-09", Won: true, SolvedStage: 1, NumStages: 4},
+		},
+		exp: Summary{
+			Played:        3,
+			Won:           3,
+			CurrentStreak: 2,
+			MaxStreak:     2,
+			Distribution:  []int{3, 0, 0, 0},
+		},
+	}, {
+		desc: "replays don't count towards streaks",
+		games: []Game{
+			{Date: "2024-05-06", Won: true, SolvedStage: 1, NumStages: 4},
+			{Date: "2024-05-07", Won: true, SolvedStage: 2, NumStages: 4, Replay: true},
+			{Date: "2024-05-08", Won: false, NumStages: 4, Replay: true},
+			{Date: "2024-05-09", Won: true, SolvedStage: 1, NumStages: 4},
+		},
+		exp: Summary{
+			Played:        4,
+			Won:           3,
+			CurrentStreak: 1,
+			MaxStreak:     1,
+			Distribution:  []int{2, 1, 0, 0},
+			Lost:          1,
+		},
+	}, {
+		desc: "current streak includes today",
+		games: []Game{
+			{Date: "2024-05-09", Won: true, SolvedStage: 3, NumStages: 4},
+			{Date: "2024-05-10", Won: true, SolvedStage: 3, NumStages: 4},
+		},
+		exp: Summary{
+			Played:        2,
+			Won:           2,
+			CurrentStreak: 2,
+			MaxStreak:     2,
+			Distribution:  []int{0, 0, 2, 0},
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.exp, Summarize(tc.games, today))
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", "games.json"))
+	key := Key{
+		Repository: "/src/repo",
+		Team:       "rocket",
+		Date:       time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	_, ok, err := store.Find(key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Record(key, Game{Guesses: []string{"a@example.com"}, NumStages: 4}))
+	require.NoError(t, store.Record(key, Game{Guesses: []string{"b@example.com"}, Won: true, SolvedStage: 1, NumStages: 4}))
+
+	game, ok, err := store.Find(key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, Game{
+		Repository:  "/src/repo",
+		Team:        "rocket",
+		Date:        "2024-05-10",
+		Guesses:     []string{"b@example.com"},
+		Won:         true,
+		SolvedStage: 1,
+		NumStages:   4,
+	}, game)
+
+	games, err := store.GamesFor("/src/repo", "")
+	require.NoError(t, err)
+	assert.Empty(t, games)
+}
@@ -0,0 +1,244 @@
+// Package webhook posts game results and announcements to chat webhooks.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/config"
+)
+
+// The kinds of messages that are posted.
+const (
+	// EventResult is posted when a player finishes a daily game.
+	EventResult = "result"
+	// EventAnnouncement is posted to announce the answer to a daily game and the team's results.
+	EventAnnouncement = "announcement"
+)
+
+// Message is posted to every webhook. JSON webhooks are posted the whole message, while Slack webhooks are only
+// posted the text.
+type Message struct {
+	// Event is the kind of message. One of the Event constants.
+	Event string `json:"event"`
+	// Text is a human-readable version of the message.
+	Text string `json:"text"`
+	// Result is only set for EventResult messages.
+	Result *Result `json:"result,omitempty"`
+	// Announcement is only set for EventAnnouncement messages.
+	Announcement *Announcement `json:"announcement,omitempty"`
+}
+
+// Result is a player's result for a daily game.
+type Result struct {
+	Player       string `json:"player,omitempty"`
+	PuzzleNumber int    `json:"puzzle_number"`
+	Repository   string `json:"repository"`
+	Won          bool   `json:"won"`
+	Guesses      int    `json:"guesses"`
+	// SolvedStage is the 1-indexed stage the puzzle was solved at, or 0 if it wasn't solved.
+	SolvedStage int `json:"solved_stage"`
+	NumStages   int `json:"num_stages"`
+	// Share is the spoiler-free summary of the result.
+	Share string `json:"share"`
+	// Stats are the player's statistics including this game.
+	Stats Stats `json:"stats"`
+}
+
+type Stats struct {
+	Played        int `json:"played"`
+	Won           int `json:"won"`
+	CurrentStreak int `json:"current_streak"`
+	MaxStreak     int `json:"max_streak"`
+}
+
+// Announcement is the answer to a daily game and how the team did.
+type Announcement struct {
+	PuzzleNumber int    `json:"puzzle_number"`
+	Repository   string `json:"repository"`
+	AnswerName   string `json:"answer_name"`
+	AnswerEmail  string `json:"answer_email"`
+	// Players is how many players submitted a result.
+	Players int `json:"players"`
+	// Solved is how many players guessed the author.
+	Solved int `json:"solved"`
+	// Distribution is the number of players that solved the game at each stage. The first element is for the first stage.
+	Distribution []int `json:"distribution"`
+	// AverageGuesses is the average number of guesses taken by the players that guessed the author.
+	AverageGuesses float64 `json:"average_guesses"`
+}
+
+// NewResultMessage creates the message posted when a player finishes a daily game.
+func NewResultMessage(result Result) Message {
+	var sb strings.Builder
+	if result.Player != "" {
+		fmt.Fprintf(&sb, "%s's result:\n", result.Player)
+	}
+	sb.WriteString(result.Share)
+	fmt.Fprintf(&sb, "Played %d | Won %d | Streak %d | Max streak %d", result.Stats.Played, result.Stats.Won, result.Stats.CurrentStreak, result.Stats.MaxStreak)
+
+	return Message{
+		Event:  EventResult,
+		Text:   sb.String(),
+		Result: &result,
+	}
+}
+
+// NewAnnouncementMessage creates the message that announces the answer to a daily game.
+func NewAnnouncementMessage(announcement Announcement) Message {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The answer to gauthordle #%d %s was %s (%s).\n", announcement.PuzzleNumber, announcement.Repository, announcement.AnswerName, announcement.AnswerEmail)
+	switch {
+	case announcement.Players == 0:
+		sb.WriteString("Nobody submitted a result.")
+	case announcement.Solved == 0:
+		fmt.Fprintf(&sb, "None of the %d players guessed the author.", announcement.Players)
+	default:
+		fmt.Fprintf(&sb, "%d of %d players guessed the author, taking %.2f guesses on average.", announcement.Solved, announcement.Players, announcement.AverageGuesses)
+
+		var stages []string
+		for i, count := range announcement.Distribution {
+			stages = append(stages, fmt.Sprintf("%d: %d", i+1, count))
+		}
+		fmt.Fprintf(&sb, "\nSolved on guess %s", strings.Join(stages, ", "))
+	}
+
+	return Message{
+		Event:        EventAnnouncement,
+		Text:         sb.String(),
+		Announcement: &announcement,
+	}
+}
+
+// Sender posts messages to webhooks.
+type Sender struct {
+	hooks  []config.Webhook
+	client *http.Client
+	// attempts is the most times a message is posted to each webhook.
+	attempts int
+	// backoff is how long to wait before the first retry. It doubles after each retry.
+	backoff time.Duration
+}
+
+type Option func(*Sender)
+
+// WithTimeout sets how long each attempt to post a message can take. Defaults to 10 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Sender) {
+		s.client.Timeout = timeout
+	}
+}
+
+// WithAttempts sets the most times a message is posted to each webhook when it fails. Defaults to 3.
+func WithAttempts(attempts int) Option {
+	return func(s *Sender) {
+		s.attempts = attempts
+	}
+}
+
+// WithBackoff sets how long to wait before retrying a failed post. It doubles after each retry. Defaults to 1 second.
+func WithBackoff(backoff time.Duration) Option {
+	return func(s *Sender) {
+		s.backoff = backoff
+	}
+}
+
+func NewSender(hooks []config.Webhook, opts ...Option) (*Sender, error) {
+	hooks = slices.Clone(hooks)
+	for i, hook := range hooks {
+		if hook.URL == "" {
+			return nil, fmt.Errorf("webhook %d doesn't have a url", i+1)
+		}
+
+		switch hook.Format {
+		case "":
+			hooks[i].Format = config.WebhookFormatJSON
+		case config.WebhookFormatJSON, config.WebhookFormatSlack:
+		default:
+			return nil, fmt.Errorf("unknown webhook format %q", hook.Format)
+		}
+	}
+
+	s := &Sender{
+		hooks:    hooks,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		attempts: 3,
+		backoff:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.attempts < 1 {
+		return nil, errors.New("webhooks must be attempted at least once")
+	}
+
+	return s, nil
+}
+
+// Send posts the message to every webhook. A failure to post to one webhook doesn't stop it from being posted to
+// the others.
+func (s *Sender) Send(message Message) error {
+	var errs []error
+	for _, hook := range s.hooks {
+		err := s.post(hook, message)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error posting to webhook %s: %w", hook.URL, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// post posts the message to the webhook, retrying if the failure might be temporary.
+func (s *Sender) post(hook config.Webhook, message Message) error {
+	var payload any = message
+	if hook.Format == config.WebhookFormatSlack {
+		payload = slackMessage{Text: message.Text}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := s.attempt(hook.URL, body)
+		if err == nil || !retry || attempt == s.attempts {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// attempt makes a single post. If it fails, it also returns whether the failure might be temporary.
+func (s *Sender) attempt(url string, body []byte) (bool, error) {
+	response, err := s.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+
+	// Server errors and rate limiting are worth retrying, but any other error will happen again.
+	retry := response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("the webhook responded with %s", response.Status)
+}
@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/josephnaberhaus/gauthordle/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stub is a local webhook that fails the first failures requests with the given status.
+type stub struct {
+	failures int32
+	status   int
+	delay    time.Duration
+
+	requests atomic.Int32
+	body     atomic.Value
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := s.requests.Add(1)
+	body, _ := io.ReadAll(r.Body)
+	s.body.Store(string(body))
+
+	time.Sleep(s.delay)
+	if request <= s.failures {
+		w.WriteHeader(s.status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestSender_Formats(t *testing.T) {
+	slack, generic := &stub{}, &stub{}
+	slackServer, genericServer := httptest.NewServer(slack), httptest.NewServer(generic)
+	defer slackServer.Close()
+	defer genericServer.Close()
+
+	sender, err := NewSender([]config.Webhook{
+		{URL: slackServer.URL, Format: config.WebhookFormatSlack},
+		{URL: genericServer.URL},
+	})
+	require.NoError(t, err)
+
+	message := NewResultMessage(Result{
+		Player:       "Alice",
+		PuzzleNumber: 12,
+		Repository:   "repo",
+		Won:          true,
+		Guesses:      2,
+		SolvedStage:  2,
+		NumStages:    4,
+		Share:        "gauthordle #12 repo 2/4\n🟥\n💡🟩\n",
+		Stats:        Stats{Played: 5, Won: 4, CurrentStreak: 3, MaxStreak: 3},
+	})
+	require.NoError(t, sender.Send(message))
+
+	expText := "Alice's result:\ngauthordle #12 repo 2/4\n🟥\n💡🟩\nPlayed 5 | Won 4 | Streak 3 | Max streak 3"
+	assert.JSONEq(t, `{"text": `+quote(t, expText)+`}`, slack.body.Load().(string))
+
+	var posted Message
+	require.NoError(t, json.Unmarshal([]byte(generic.body.Load().(string)), &posted))
+	assert.Equal(t, message, posted)
+	assert.Equal(t, EventResult, posted.Event)
+}
+
+func TestSender_Retries(t *testing.T) {
+	tests := []struct {
+		desc        string
+		stub        *stub
+		expRequests int32
+		expError    bool
+	}{{
+		desc:        "recovers from server errors",
+		stub:        &stub{failures: 2, status: http.StatusInternalServerError},
+		expRequests: 3,
+	}, {
+		desc:        "recovers from rate limiting",
+		stub:        &stub{failures: 1, status: http.StatusTooManyRequests},
+		expRequests: 2,
+	}, {
+		desc:        "gives up after the last attempt",
+		stub:        &stub{failures: 5, status: http.StatusBadGateway},
+		expRequests: 3,
+		expError:    true,
+	}, {
+		desc:        "doesn't retry client errors",
+		stub:        &stub{failures: 5, status: http.StatusNotFound},
+		expRequests: 1,
+		expError:    true,
+	}, {
+		desc:        "times out",
+		stub:        &stub{delay: 200 * time.Millisecond},
+		expRequests: 3,
+		expError:    true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			server := httptest.NewServer(tc.stub)
+			defer server.Close()
+
+			sender, err := NewSender(
+				[]config.Webhook{{URL: server.URL}},
+				WithAttempts(3),
+				WithBackoff(time.Millisecond),
+				WithTimeout(50*time.Millisecond),
+			)
+			require.NoError(t, err)
+
+			err = sender.Send(NewAnnouncementMessage(Announcement{PuzzleNumber: 1}))
+			if tc.expError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expRequests, tc.stub.requests.Load())
+		})
+	}
+}
+
+func TestNewAnnouncementMessage(t *testing.T) {
+	message := NewAnnouncementMessage(Announcement{
+		PuzzleNumber:   12,
+		Repository:     "repo",
+		AnswerName:     "Alice",
+		AnswerEmail:    "alice@example.com",
+		Players:        3,
+		Solved:         2,
+		Distribution:   []int{1, 0, 1, 0},
+		AverageGuesses: 2,
+	})
+	assert.Equal(t, EventAnnouncement, message.Event)
+	assert.Equal(t, "The answer to gauthordle #12 repo was Alice (alice@example.com).\n2 of 3 players guessed the author, taking 2.00 guesses on average.\nSolved on guess 1: 1, 2: 0, 3: 1, 4: 0", message.Text)
+}
+
+func TestNewSender_InvalidFormat(t *testing.T) {
+	_, err := NewSender([]config.Webhook{{URL: "http://localhost", Format: "teams"}})
+	assert.EqualError(t, err, `unknown webhook format "teams"`)
+}
+
+func quote(t *testing.T, s string) string {
+	t.Helper()
+
+	quoted, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	return string(quoted)
+}
@@ -1,33 +1,86 @@
 package main
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"strings"
+	"time"
+	// Embed the time zone database so that configured time zones work on systems without one.
+	_ "time/tzdata"
 
+	"github.com/JosephNaberhaus/prompt"
 	"github.com/josephnaberhaus/gauthordle/internal/commit"
 	"github.com/josephnaberhaus/gauthordle/internal/config"
 	"github.com/josephnaberhaus/gauthordle/internal/game"
 	"github.com/josephnaberhaus/gauthordle/internal/git"
+	"github.com/josephnaberhaus/gauthordle/internal/leaderboard"
 	"github.com/josephnaberhaus/gauthordle/internal/output"
+	"github.com/josephnaberhaus/gauthordle/internal/server"
+	"github.com/josephnaberhaus/gauthordle/internal/stats"
+	"github.com/josephnaberhaus/gauthordle/internal/webhook"
 )
 
-const helpBody = "A daily game where you try to guess the author of some Git commits.\n\nTo play, simply \"git checkout\" the main development branch of your repository\nand run this program with no arguments.\n\nNew games start at midnight Central Time."
+const helpBody = "A daily game where you try to guess the author of some Git commits.\n\nTo play, simply run this program with no arguments while the main development\nbranch of your repository is checked out, or use --ref to pick the branch.\n\nNew games start at midnight UTC unless a different time is configured.\n\nCommands:\n  announce           Post the answer to yesterday's game and your team's results to the webhooks in your config.\n  leaderboard serve  Run a server that collects your team's results and ranks the players.\n  serve              Play the game in your browser instead of the terminal.\n  stats              Show your statistics for the daily games played in this repository.\n  verify-seed        Print a fingerprint of the daily game. Players with the same fingerprint have the same game."
+
+// The ways that the game can be played.
+const (
+	modeTerminal = "terminal"
+	// modeJSON plays the game with newline-delimited JSON on stdin and stdout. See game.Puzzle.RunJSON.
+	modeJSON = "json"
+)
+
+// protocolOutput is where the events of JSON mode are written.
+var protocolOutput *os.File
 
 var (
-	dumpCommits = flag.String("debugDumpCommits", "", "File to dump JSON containing all commits considered when generating the game.")
-	help        = flag.Bool("help", false, "Print the help message.")
-	random      = flag.Bool("random", false, "If true, play a random game instead of the daily game.")
-	team        = flag.String("team", "", "Team to build the game for. This must mach a team defined in your config.")
+	addr            = flag.String("addr", "localhost:8080", "The address to listen on for the serve and leaderboard serve commands.")
+	branch          = flag.String("branch", "", "Alias for --ref.")
+	date            = flag.String("date", "", "Play the daily game for a past day instead of today, in YYYY-MM-DD format.")
+	details         = flag.String("details", "", "Comma separated details of the commits to reveal and the guess to reveal them at, e.g. \"body:3,stat:4\". The details are \"body\", \"stat\", and \"patch\". Overrides the config file.")
+	difficulty      = flag.String("difficulty", "", "The order that commits are revealed in. Either \"easy\" to show the most distinctive commits first, \"normal\", or \"hard\" to show them last. Overrides the config file.")
+	dumpCommits     = flag.String("debugDumpCommits", "", "File to dump JSON containing all commits considered when generating the game.")
+	help            = flag.Bool("help", false, "Print the help message.")
+	hints           = flag.String("hints", "", "Comma separated hints to reveal and the guess to reveal them at, e.g. \"total_commits:2,most_touched_file:4\". Overrides the config file.")
+	historyBackend  = flag.String("historyBackend", "", "How to read the git history. Either \"native\" or \"git\". Overrides the config file.")
+	leaderboardFile = flag.String("leaderboardFile", "", "The file that the leaderboard serve command saves results to and the announce command reads them from. Defaults to leaderboard.json next to your statistics.")
+	mode            = flag.String("mode", modeTerminal, "How to play the game. Either \"terminal\" or \"json\" to read guesses from stdin and write events to stdout as newline-delimited JSON.")
+	practice        = flag.Bool("practice", false, "If true, replay the daily game even if you've already played it. Practice games don't count towards your statistics.")
+	puzzleLength    = flag.Int("puzzleLength", 0, "The number of commits shown in the game, which is also the number of guesses. Overrides the config file.")
+	puzzleNumber    = flag.Int("puzzle", 0, "Play the daily game with the given number instead of today's.")
+	random          = flag.Bool("random", false, "If true, play a random game instead of the daily game.")
+	ref             = flag.String("ref", "", "The branch or revision to build the game from instead of HEAD. Overrides the config file.")
+	repos           = flag.String("repos", "", "Comma separated paths of repositories to build the game from instead of the one in the working directory. Overrides the config file.")
+	shareOutput     = flag.String("shareOutput", "", "File to also write the shareable result of the daily game to. Use \"-\" for stdout.")
+	submit          = flag.String("submit", "", "The URL of a leaderboard server to submit the result of the daily game to.")
+	team            = flag.String("team", "", "Team to build the game for. This must mach a team defined in your config.")
+	windowEnd       = flag.String("windowEnd", "", "The newest commits to build the game from. Overrides the config file. See the README for the format.")
+	windowStart     = flag.String("windowStart", "", "The oldest commits to build the game from. Overrides the config file. See the README for the format.")
 )
 
 func main() {
 	flag.Parse()
 
+	var command string
+	if len(flag.Args()) > 0 {
+		command = flag.Arg(0)
+		// Flags are allowed after the command too.
+		err := flag.CommandLine.Parse(flag.Args()[1:])
+		exitIfError(err)
+
+		// The leaderboard command has its own subcommands.
+		if command == "leaderboard" && len(flag.Args()) > 0 {
+			command += " " + flag.Arg(0)
+			err := flag.CommandLine.Parse(flag.Args()[1:])
+			exitIfError(err)
+		}
+	}
+
 	if len(flag.Args()) > 0 {
 		exit(fmt.Errorf("unsupported arguments %q\n", strings.Join(flag.Args(), ",")))
 	}
@@ -36,54 +89,476 @@ func main() {
 		showUsage()
 	}
 
-	if !git.IsGitInstalled() {
-		exit(errors.New("git must be installed"))
+	switch *mode {
+	case modeTerminal:
+	case modeJSON:
+		// Stdout is reserved for the protocol, so everything else is shown on stderr instead.
+		protocolOutput = os.Stdout
+		os.Stdout = os.Stderr
+	default:
+		exit(fmt.Errorf("unknown --mode %q", *mode))
+	}
+
+	cfg, err := config.Load()
+	exitIfError(err)
+
+	if *team != "" {
+		if _, ok := cfg.Teams[*team]; !ok {
+			exit(fmt.Errorf("team %q doesn't exist in your config file", *team))
+		}
+	}
+
+	if *submit != "" {
+		if *random || *practice {
+			exit(errors.New("only daily games can be submitted to a leaderboard, so --submit can't be used with --random or --practice"))
+		}
+		if cfg.Player == "" {
+			exit(errors.New("set \"player\" in your config file to submit results to a leaderboard"))
+		}
+	}
+
+	// Check the webhooks before playing so that a typo doesn't lose the result.
+	_, err = webhook.NewSender(cfg.Webhooks)
+	exitIfError(err)
+
+	schedule, err := game.NewSchedule(cfg.Timezone, cfg.RolloverHour)
+	exitIfError(err)
+
+	repos := openRepositories(cfg)
+
+	switch command {
+	case "":
+		play(cfg, schedule, repos)
+	case "announce":
+		announce(cfg, schedule, repos)
+	case "leaderboard serve":
+		serveLeaderboard(cfg, schedule, repos)
+	case "serve":
+		serve(cfg, schedule, repos)
+	case "stats":
+		showStats(schedule, repos)
+	case "verify-seed":
+		verifySeed(cfg, schedule, repos)
+	default:
+		exit(fmt.Errorf("unknown command %q", command))
+	}
+
+	exitIfError(repos.Close())
+}
+
+func play(cfg config.Config, schedule game.Schedule, repos git.Repositories) {
+	day := puzzleDay(schedule)
+
+	recordGame := !*random && !*practice
+	if recordGame && alreadyPlayed(repos, day, schedule) {
+		return
+	}
+
+	puzzle, warnings := buildPuzzle(cfg, schedule, repos, day)
+	if len(warnings) > 0 {
+		showHistoryWarnings(warnings)
+		showFingerprint(puzzle)
+
+		// There's no one to ask when the game is played by another program.
+		if *mode == modeTerminal {
+			confirm := &prompt.Boolean{Question: "Play anyway?"}
+			err := confirm.Show()
+			exitIfError(err)
+			if !confirm.Response() {
+				return
+			}
+		}
+	}
+
+	var result game.Result
+	var err error
+	if *mode == modeJSON {
+		result, err = puzzle.RunJSON(os.Stdin, protocolOutput)
+	} else {
+		result, err = puzzle.Run()
+	}
+	exitIfError(err)
+
+	if !*random {
+		showShareString(result, day, repos)
+	}
+
+	if recordGame {
+		err := recordResult(schedule, repos, day, result)
+		exitIfError(err)
+
+		err = postResult(cfg, schedule, repos, day, result)
+		exitIfError(err)
+	}
+
+	if *submit != "" {
+		err := submitResult(cfg, puzzle, day, result)
+		exitIfError(err)
+	}
+}
+
+// serve plays the game in the browser instead of the terminal.
+func serve(cfg config.Config, schedule game.Schedule, repos git.Repositories) {
+	day := puzzleDay(schedule)
+
+	recordGame := !*random && !*practice
+	if recordGame && alreadyPlayed(repos, day, schedule) {
+		return
+	}
+
+	puzzle, warnings := buildPuzzle(cfg, schedule, repos, day)
+	if len(warnings) > 0 {
+		showHistoryWarnings(warnings)
+		showFingerprint(puzzle)
+	}
+
+	options := []server.Option{
+		server.WithRepository(repos.Label()),
+		server.WithResultHandler(func(result game.Result) {
+			if !recordGame {
+				return
+			}
+
+			err := recordResult(schedule, repos, day, result)
+			if err != nil {
+				output.FprintColor(os.Stderr, fmt.Sprintf("ERROR: failed to save the result: %s\n", err.Error()), output.Red)
+			}
+
+			err = postResult(cfg, schedule, repos, day, result)
+			if err != nil {
+				output.FprintColor(os.Stderr, fmt.Sprintf("ERROR: %s\n", err.Error()), output.Red)
+			}
+
+			if *submit != "" {
+				err := submitResult(cfg, puzzle, day, result)
+				if err != nil {
+					output.FprintColor(os.Stderr, fmt.Sprintf("ERROR: %s\n", err.Error()), output.Red)
+				}
+			}
+		}),
+	}
+	if !*random {
+		options = append(options, server.WithPuzzleNumber(game.PuzzleNumber(day)))
+	}
+
+	output.PrintColorLn(fmt.Sprintf("Open http://%s in your browser to play. Press Ctrl+C to stop.", *addr), output.Green)
+	err := http.ListenAndServe(*addr, server.New(puzzle, options...))
+	exitIfError(err)
+}
+
+// alreadyPlayed shows the saved result if the daily game has already been played.
+// The daily game can only be played once so that you can't retry with the answer already known.
+func alreadyPlayed(repos git.Repositories, day time.Time, schedule game.Schedule) bool {
+	store, err := stats.Open()
+	exitIfError(err)
+
+	saved, ok, err := store.Find(dailyGameKey(repos, day))
+	exitIfError(err)
+	if ok {
+		showAlreadyPlayed(saved, day, schedule)
+	}
+
+	return ok
+}
+
+func recordResult(schedule game.Schedule, repos git.Repositories, day time.Time, result game.Result) error {
+	store, err := stats.Open()
+	if err != nil {
+		return err
+	}
+
+	return store.Record(dailyGameKey(repos, day), stats.Game{
+		Guesses:     result.Guesses,
+		Won:         result.Won,
+		SolvedStage: result.SolvedStage(),
+		NumStages:   result.NumStages,
+		Replay:      isReplay(schedule, day),
+	})
+}
+
+// isReplay returns whether the daily game is being played after its day was over.
+func isReplay(schedule game.Schedule, day time.Time) bool {
+	return !day.Equal(schedule.Today())
+}
+
+// postResult posts the result of a daily game and the player's statistics to the webhooks in the config file.
+// Replays of past games aren't posted, since they aren't today's result.
+func postResult(cfg config.Config, schedule game.Schedule, repos git.Repositories, day time.Time, result game.Result) error {
+	if len(cfg.Webhooks) == 0 || isReplay(schedule, day) {
+		return nil
+	}
+
+	sender, err := webhook.NewSender(cfg.Webhooks)
+	if err != nil {
+		return err
+	}
+
+	store, err := stats.Open()
+	if err != nil {
+		return err
+	}
+	games, err := store.GamesFor(repos.ID(), *team)
+	if err != nil {
+		return err
+	}
+	summary := stats.Summarize(games, schedule.Today())
+
+	err = sender.Send(webhook.NewResultMessage(webhook.Result{
+		Player:       cfg.Player,
+		PuzzleNumber: game.PuzzleNumber(day),
+		Repository:   repos.Label(),
+		Won:          result.Won,
+		Guesses:      len(result.Guesses),
+		SolvedStage:  result.SolvedStage(),
+		NumStages:    result.NumStages,
+		Share:        result.ShareString(game.PuzzleNumber(day), repos.Label()),
+		Stats: webhook.Stats{
+			Played:        summary.Played,
+			Won:           summary.Won,
+			CurrentStreak: summary.CurrentStreak,
+			MaxStreak:     summary.MaxStreak,
+		},
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to post the result: %w", err)
+	}
+
+	output.PrintColorLn("Your result was posted to the webhooks.", output.Green)
+	return nil
+}
+
+// announce posts the answer to a daily game and the team's results on the leaderboard to the webhooks in the config
+// file. It announces yesterday's game unless --date or --puzzle is given.
+func announce(cfg config.Config, schedule game.Schedule, repos git.Repositories) {
+	if len(cfg.Webhooks) == 0 {
+		exit(errors.New("there aren't any webhooks in your config file to announce to"))
+	}
+	if *random || *practice {
+		exit(errors.New("--random and --practice can't be used with announce"))
+	}
+
+	var day time.Time
+	if *date == "" && *puzzleNumber == 0 {
+		day = schedule.PuzzleDay(game.PuzzleNumber(schedule.Today()) - 1)
+		if game.PuzzleNumber(day) < 1 {
+			exit(errors.New("there isn't a game from yesterday to announce"))
+		}
+	} else {
+		day = puzzleDay(schedule)
+	}
+	// Announcing the answer while the game can still be played would spoil it.
+	if day.Equal(schedule.Today()) {
+		exit(errors.New("today's game can't be announced until it's over"))
 	}
 
-	if !git.IsInGitRepo() {
-		exit(errors.New("must be in a git repository"))
+	puzzle, warnings := buildPuzzle(cfg, schedule, repos, day)
+	if len(warnings) > 0 {
+		showHistoryWarnings(warnings)
 	}
 
+	store := leaderboard.NewStore(leaderboardPath())
+	submissions, err := store.Submissions()
+	exitIfError(err)
+
+	answer := puzzle.Answer()
+	results := leaderboard.SummarizeGame(submissions, game.PuzzleNumber(day), puzzle.NumStages())
+	announcement := webhook.Announcement{
+		PuzzleNumber:   game.PuzzleNumber(day),
+		Repository:     repos.Label(),
+		AnswerName:     answer.Name,
+		AnswerEmail:    answer.Email,
+		Players:        results.Players,
+		Solved:         results.Solved,
+		Distribution:   results.Distribution,
+		AverageGuesses: results.AverageGuesses,
+	}
+
+	sender, err := webhook.NewSender(cfg.Webhooks)
+	exitIfError(err)
+	err = sender.Send(webhook.NewAnnouncementMessage(announcement))
+	exitIfError(err)
+
+	output.PrintColorLn(fmt.Sprintf("Announced the answer to game #%d.", announcement.PuzzleNumber), output.Green)
+}
+
+// submitResult sends the result of a daily game to the leaderboard server given by --submit.
+func submitResult(cfg config.Config, puzzle game.Puzzle, day time.Time, result game.Result) error {
+	err := leaderboard.Submit(*submit, leaderboard.Submission{
+		Player:       cfg.Player,
+		PuzzleNumber: game.PuzzleNumber(day),
+		Fingerprint:  puzzle.Fingerprint(),
+		Guesses:      len(result.Guesses),
+		SolvedStage:  result.SolvedStage(),
+		NumStages:    result.NumStages,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to submit the result to the leaderboard: %w", err)
+	}
+
+	output.PrintColorLn("Your result was submitted to the leaderboard.", output.Green)
+	return nil
+}
+
+// serveLeaderboard runs a server that collects the results of the daily games and ranks the players.
+func serveLeaderboard(cfg config.Config, schedule game.Schedule, repos git.Repositories) {
+	if *random || *practice {
+		exit(errors.New("--random and --practice can't be used with the leaderboard"))
+	}
+
+	path := leaderboardPath()
+
+	// Build today's game up front so that problems with the history are shown before any results are submitted.
+	today := schedule.Today()
+	puzzle, warnings := buildPuzzle(cfg, schedule, repos, today)
+	if len(warnings) > 0 {
+		showHistoryWarnings(warnings)
+	}
+	// The history is opened once and shared by every game that's built.
+	history, err := repos.History(cmp.Or(*historyBackend, cfg.HistoryBackend))
+	exitIfError(err)
+	puzzles := leaderboard.NewDailyPuzzles(schedule, func(day time.Time) (game.Puzzle, error) {
+		puzzle, _, err := newPuzzle(cfg, schedule, history, day, "")
+		return puzzle, err
+	})
+	puzzles.Add(game.PuzzleNumber(today), puzzle)
+
+	output.PrintColorLn(fmt.Sprintf("Serving the leaderboard at http://%s. Results are saved to %s.", *addr, path), output.Green)
+	err = http.ListenAndServe(*addr, leaderboard.NewServer(leaderboard.NewStore(path), puzzles))
+	exitIfError(err)
+}
+
+// leaderboardPath returns the file that the leaderboard's results are saved to.
+func leaderboardPath() string {
+	if *leaderboardFile != "" {
+		return *leaderboardFile
+	}
+
+	path, err := leaderboard.DefaultPath()
+	exitIfError(err)
+
+	return path
+}
+
+// buildPuzzle builds the game for the given day, or a random game if --random is set.
+// For daily games, it also returns warnings about why the history might not match other players'.
+func buildPuzzle(cfg config.Config, schedule game.Schedule, repos git.Repositories, day time.Time) (game.Puzzle, []string) {
 	fmt.Println("Building game...")
 
-	startTime, endTime := game.PuzzleTimeRange()
-	cfg, err := config.Load()
+	history, err := repos.History(cmp.Or(*historyBackend, cfg.HistoryBackend))
 	exitIfError(err)
+	puzzle, endTime, err := newPuzzle(cfg, schedule, history, day, *dumpCommits)
+	exitIfError(err)
+
+	if *random {
+		return puzzle, nil
+	}
+
+	var warnings []string
+	for _, repo := range repos {
+		for _, warning := range historyWarnings(repo, gameRef(cfg), endTime) {
+			if len(repos) > 1 {
+				warning = repo.Name() + ": " + warning
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return puzzle, warnings
+}
+
+// newPuzzle builds the game for the given day from the history, or a random game if --random is set. If dumpFile isn't
+// empty, the commits that the game could use are written to it as JSON.
+// It also returns the time of the newest commits that the game could use.
+func newPuzzle(cfg config.Config, schedule game.Schedule, history git.History, day time.Time, dumpFile string) (game.Puzzle, time.Time, error) {
+	window, err := game.ParseWindow(cmp.Or(*windowStart, cfg.WindowStart), cmp.Or(*windowEnd, cfg.WindowEnd))
+	if err != nil {
+		return game.Puzzle{}, time.Time{}, err
+	}
+
+	startTime, endTime, err := schedule.TimeRange(day, window, history)
+	if err != nil {
+		return game.Puzzle{}, time.Time{}, err
+	}
 
 	// Get the commits for this game.
 	filterOptions := []commit.FilterOption{
 		commit.WithConfig(cfg),
+		commit.WithHistory(history),
+		commit.WithRef(gameRef(cfg)),
 		commit.WithStartTime(startTime),
 		commit.WithEndTime(endTime),
 	}
 	if *team != "" {
-		if _, ok := cfg.Teams[*team]; !ok {
-			exit(fmt.Errorf("team %q doesn't exist in your config file", *team))
-		}
-
 		filterOptions = append(filterOptions, commit.WithTeam(*team))
 	}
 
 	filter, err := commit.BuildFilter(filterOptions...)
-	exitIfError(err)
+	if err != nil {
+		return game.Puzzle{}, time.Time{}, err
+	}
 	commits, err := filter.GetCommits()
-	exitIfError(err)
+	if err != nil {
+		return game.Puzzle{}, time.Time{}, err
+	}
 
-	if *dumpCommits != "" {
+	if dumpFile != "" {
 		serializedCommits, err := json.MarshalIndent(commits, "", "  ")
-		exitIfError(err)
+		if err != nil {
+			return game.Puzzle{}, time.Time{}, err
+		}
 
-		err = os.WriteFile(*dumpCommits, serializedCommits, os.ModePerm)
-		exitIfError(err)
+		err = os.WriteFile(dumpFile, serializedCommits, os.ModePerm)
+		if err != nil {
+			return game.Puzzle{}, time.Time{}, err
+		}
 	}
 
-	// Build and run the game.
+	// Build the game.
 	gameOptions := []game.Option{
 		game.WithCommits(commits),
+		game.WithHistory(history),
+		game.WithRedactor(filter.Redactor()),
+		game.WithCoAuthorsAccepted(cfg.CoAuthors == config.CoAuthorsAccept),
 	}
 	if !*random {
-		// For non-random games, use the startTime as the random source so that it's stable throughout the day.
-		gameOptions = append(gameOptions, game.WithRandomSource(rand.NewSource(startTime.Unix())))
+		// For non-random games, use the puzzle number as the random source so that it's stable throughout the day.
+		gameOptions = append(gameOptions, game.WithRandomSource(rand.NewSource(int64(game.PuzzleNumber(day)))))
+	}
+	gameDifficulty, err := game.ParseDifficulty(cmp.Or(*difficulty, cfg.Difficulty))
+	if err != nil {
+		return game.Puzzle{}, time.Time{}, err
+	}
+	gameOptions = append(gameOptions, game.WithDifficulty(gameDifficulty))
+	if length := cmp.Or(*puzzleLength, cfg.PuzzleLength); length != 0 {
+		gameOptions = append(gameOptions, game.WithNumCommits(length))
+	}
+	switch {
+	case *hints != "":
+		hintStages, err := game.ParseHints(*hints)
+		if err != nil {
+			return game.Puzzle{}, time.Time{}, fmt.Errorf("invalid --hints: %w", err)
+		}
+		gameOptions = append(gameOptions, game.WithHints(hintStages))
+	case len(cfg.Hints) > 0:
+		var hintStages []game.HintStage
+		for _, hint := range cfg.Hints {
+			hintStages = append(hintStages, game.HintStage{Name: hint.Name, Stage: hint.Stage})
+		}
+		gameOptions = append(gameOptions, game.WithHints(hintStages))
+	}
+	switch {
+	case *details != "":
+		detailStages, err := game.ParseDetails(*details)
+		if err != nil {
+			return game.Puzzle{}, time.Time{}, fmt.Errorf("invalid --details: %w", err)
+		}
+		gameOptions = append(gameOptions, game.WithDetails(detailStages))
+	case len(cfg.Details) > 0:
+		var detailStages []game.DetailStage
+		for _, detail := range cfg.Details {
+			detailStages = append(detailStages, game.DetailStage{Name: detail.Name, Stage: detail.Stage})
+		}
+		gameOptions = append(gameOptions, game.WithDetails(detailStages))
 	}
 	if cfg.AuthorBias != nil {
 		gameOptions = append(gameOptions, game.WithAuthorBias(*cfg.AuthorBias))
@@ -92,10 +567,203 @@ func main() {
 	}
 
 	puzzle, err := game.BuildPuzzle(gameOptions...)
+	if err != nil {
+		return game.Puzzle{}, time.Time{}, err
+	}
+
+	return puzzle, endTime, nil
+}
+
+// historyWarnings checks whether the repository's history may be missing commits that other players have.
+func historyWarnings(repo *git.Repository, ref string, endTime time.Time) []string {
+	ref = cmp.Or(ref, "HEAD")
+	status, err := git.NewNativeHistory(repo).Status(ref)
 	exitIfError(err)
 
-	err = puzzle.Run()
+	name := strings.TrimPrefix(strings.TrimPrefix(cmp.Or(status.Ref, ref), "refs/heads/"), "refs/remotes/")
+	upstream := strings.TrimPrefix(status.Upstream, "refs/remotes/")
+
+	var warnings []string
+	if status.Shallow {
+		warnings = append(warnings, "This repository is a shallow clone, so older commits are missing. Run \"git fetch --unshallow\" to get the full history.")
+	}
+	if status.Behind {
+		warnings = append(warnings, fmt.Sprintf("%s is behind %s. Run \"git pull\" to get the latest commits.", name, upstream))
+	}
+	if status.Ahead {
+		warnings = append(warnings, fmt.Sprintf("%s has commits that aren't in %s yet.", name, upstream))
+	}
+	if status.NewestCommit.Before(endTime) {
+		warnings = append(warnings, fmt.Sprintf("The newest commit on %s is from %s, but the game uses commits up to %s. Run \"git fetch\" or \"git pull\" if your history is out of date.",
+			name, status.NewestCommit.Format(time.DateOnly), endTime.Format(time.DateOnly)))
+	}
+
+	return warnings
+}
+
+func showFingerprint(puzzle game.Puzzle) {
+	output.PrintColorLn(fmt.Sprintf("This game's fingerprint is %s. Compare it with the output of \"gauthordle verify-seed\" on a teammate's machine.", puzzle.Fingerprint()), output.White)
+	output.Ln()
+}
+
+func showHistoryWarnings(warnings []string) {
+	output.PrintColorLn("Your git history may not match your teammates', so you could get a different game:", output.Yellow)
+	for _, warning := range warnings {
+		output.PrintColorLn("  - "+warning, output.Yellow)
+	}
+	output.Ln()
+}
+
+// verifySeed prints a fingerprint of the daily game so that players can check they have the same game.
+func verifySeed(cfg config.Config, schedule game.Schedule, repos git.Repositories) {
+	if *random {
+		exit(errors.New("--random games can't be verified"))
+	}
+
+	day := puzzleDay(schedule)
+	puzzle, warnings := buildPuzzle(cfg, schedule, repos, day)
+	if len(warnings) > 0 {
+		showHistoryWarnings(warnings)
+	}
+	fmt.Printf("gauthordle #%d %s %s\n", game.PuzzleNumber(day), repos.Label(), puzzle.Fingerprint())
+}
+
+// gameRef returns the revision to build the game from. Empty means HEAD.
+func gameRef(cfg config.Config) string {
+	if *ref != "" && *branch != "" {
+		exit(errors.New("only one of --ref and --branch can be specified"))
+	}
+
+	return cmp.Or(*ref, *branch, cfg.DefaultRef)
+}
+
+// puzzleDay returns the day of the daily game to play.
+func puzzleDay(schedule game.Schedule) time.Time {
+	if *date != "" && *puzzleNumber != 0 {
+		exit(errors.New("only one of --date and --puzzle can be specified"))
+	}
+	if (*date != "" || *puzzleNumber != 0) && *random {
+		exit(errors.New("--random can't be used with --date or --puzzle"))
+	}
+
+	today := schedule.Today()
+	day := today
+	switch {
+	case *date != "":
+		var err error
+		day, err = schedule.ParseDay(*date)
+		if err != nil {
+			exit(fmt.Errorf("invalid --date %q, expected YYYY-MM-DD", *date))
+		}
+	case *puzzleNumber != 0:
+		if *puzzleNumber < 1 {
+			exit(errors.New("--puzzle must be at least 1"))
+		}
+		day = schedule.PuzzleDay(*puzzleNumber)
+	}
+
+	if day.After(today) {
+		exit(errors.New("can't play a future game"))
+	}
+	if game.PuzzleNumber(day) < 1 {
+		exit(fmt.Errorf("the first game was on %s", schedule.PuzzleDay(1).Format(time.DateOnly)))
+	}
+
+	return day
+}
+
+func showShareString(result game.Result, day time.Time, repos git.Repositories) {
+	share := result.ShareString(game.PuzzleNumber(day), repos.Label())
+
+	output.PrintColorLn("Share your result:", output.Green)
+	output.Ln()
+	output.PrintColorLn(share, output.White)
+
+	switch *shareOutput {
+	case "":
+	case "-":
+		fmt.Print(share)
+	default:
+		err := os.WriteFile(*shareOutput, []byte(share), 0o644)
+		exitIfError(err)
+	}
+}
+
+func showAlreadyPlayed(saved stats.Game, day time.Time, schedule game.Schedule) {
+	isToday := day.Equal(schedule.Today())
+	if isToday {
+		output.PrintColorLn("You've already played today's game.", output.Yellow)
+	} else {
+		output.PrintColorLn(fmt.Sprintf("You've already played game #%d from %s.", game.PuzzleNumber(day), day.Format(time.DateOnly)), output.Yellow)
+	}
+	output.Ln()
+
+	if saved.Won {
+		output.PrintColorLn(fmt.Sprintf("You guessed the author on guess %d of %d.", saved.SolvedStage, saved.NumStages), output.Green)
+	} else {
+		output.PrintColorLn("You didn't guess the author.", output.Red)
+	}
+
+	if isToday {
+		untilNext := time.Until(schedule.NextPuzzleTime()).Round(time.Minute)
+		output.PrintColorLn(fmt.Sprintf("The next game starts in %s.", formatDuration(untilNext)), output.White)
+	}
+	output.PrintColorLn("Run with --practice to play the game again without it counting towards your statistics.", output.White)
+}
+
+// formatDuration formats a duration as hours and minutes, e.g. "5h 12m".
+func formatDuration(d time.Duration) string {
+	d = max(d, 0)
+	return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func showStats(schedule game.Schedule, repos git.Repositories) {
+	store, err := stats.Open()
+	exitIfError(err)
+
+	games, err := store.GamesFor(repos.ID(), *team)
+	exitIfError(err)
+
+	stats.Summarize(games, schedule.Today()).Print()
+}
+
+// dailyGameKey identifies the daily game on the given day for the repositories and team.
+func dailyGameKey(repos git.Repositories, day time.Time) stats.Key {
+	return stats.Key{
+		Repository: repos.ID(),
+		Team:       *team,
+		Date:       day,
+	}
+}
+
+// openRepositories opens the repositories listed by --repos or the config file.
+// If there aren't any, the repository in the working directory is opened.
+func openRepositories(cfg config.Config) git.Repositories {
+	var dirs []string
+	switch {
+	case *repos != "":
+		dirs = strings.Split(*repos, ",")
+	case len(cfg.Repos) > 0:
+		var err error
+		dirs, err = cfg.RepoDirs()
+		exitIfError(err)
+	default:
+		wd, err := os.Getwd()
+		exitIfError(err)
+
+		repo, err := git.FindRepository(wd)
+		if errors.Is(err, git.ErrNotRepository) {
+			exit(errors.New("must be in a git repository"))
+		}
+		exitIfError(err)
+
+		return git.Repositories{repo}
+	}
+
+	result, err := git.OpenRepositories(dirs)
 	exitIfError(err)
+
+	return result
 }
 
 func showUsage() {